@@ -0,0 +1,279 @@
+package bracket
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/McMackety/nevermore/database"
+	"github.com/McMackety/nevermore/enums"
+	"github.com/McMackety/nevermore/events"
+	"github.com/McMackety/nevermore/scoring"
+)
+
+// The database keeps levels as ints
+const playoffLevel = int(enums.PLAYOFF)
+
+// How many overtime matches are played before the series goes to the better seed
+const maxOvertimeMatches = 3
+
+// Series is a set of matches between two alliances, the first alliance to WinsNeeded wins the series
+type Series struct {
+	Name       string `json:"name"`
+	RedSource  string `json:"redSource"`  // Where red comes from, like "A1" or "WM3", shown until the alliance is known
+	BlueSource string `json:"blueSource"` // Where blue comes from, like "A8" or "LM1", shown until the alliance is known
+	Red        int    `json:"red"`        // The alliance number, 0 until it's known
+	Blue       int    `json:"blue"`       // The alliance number, 0 until it's known
+	WinsNeeded int    `json:"winsNeeded"`
+	RedWins    int    `json:"redWins"`
+	BlueWins   int    `json:"blueWins"`
+	Ties       int    `json:"ties"` // Matches tied even after the tiebreakers, each one is replayed
+	Winner     int    `json:"winner"`
+	Loser      int    `json:"loser"`
+
+	// A series goes to overtime when it's played as many matches as it could take without a tie and still has no winner,
+	// the first alliance to win an overtime match wins the series
+	Overtime        bool `json:"overtime"`
+	OvertimeMatches int  `json:"overtimeMatches"`
+
+	red  source
+	blue source
+}
+
+// Bracket is the whole playoff bracket, it's published as the bracketUpdated event every time a playoff match is committed
+type Bracket struct {
+	Format   Format    `json:"format"`
+	Series   []*Series `json:"series"`
+	Champion int       `json:"champion"` // The alliance that won the finals, 0 until they're over
+}
+
+// Makes the bracket from the alliances picked in alliance selection and schedules the first matches.
+// This replaces any playoff matches that were already scheduled or played.
+func Generate(format Format) (*Bracket, error) {
+	allianceSelection, err := database.GetAllianceSelection()
+	if err != nil {
+		return nil, err
+	}
+	alliances := database.GetAlliances()
+	if _, err := templates(format, len(alliances)); err != nil {
+		return nil, err
+	}
+	for _, alliance := range alliances {
+		if len(pickedTeams(alliance)) < allianceSelection.TeamsPerAlliance {
+			return nil, errors.New("alliance selection isn't finished")
+		}
+	}
+	// Old results would be counted towards the new bracket since they're matched up by match number
+	if err := database.DeleteMatchResults(playoffLevel); err != nil {
+		return nil, err
+	}
+	if err := database.ReplaceSchedule(playoffLevel, nil); err != nil {
+		return nil, err
+	}
+	if err := database.CreatePlayoffBracket(&database.PlayoffBracket{Format: string(format), AllianceCount: len(alliances)}); err != nil {
+		return nil, err
+	}
+	return Advance()
+}
+
+// Works out the bracket from the committed playoff results
+func GetBracket() (*Bracket, error) {
+	playoffBracket, err := database.GetCurrentPlayoffBracket()
+	if err != nil {
+		return nil, err
+	}
+	series, err := templates(Format(playoffBracket.Format), playoffBracket.AllianceCount)
+	if err != nil {
+		return nil, err
+	}
+	bracket := &Bracket{Format: Format(playoffBracket.Format)}
+	for _, template := range series {
+		bracket.Series = append(bracket.Series, &Series{
+			Name:       template.name,
+			RedSource:  template.red.String(),
+			BlueSource: template.blue.String(),
+			WinsNeeded: template.winsNeeded,
+			red:        template.red,
+			blue:       template.blue,
+		})
+	}
+	bracket.fillAlliances()
+
+	results := make(map[int]database.MatchResult)
+	for _, result := range database.GetMatchResults(playoffLevel) {
+		// Results are oldest first, so the last time a match was played wins
+		results[result.MatchNumber] = result
+	}
+	rules := scoring.CreateRankingRules()
+	for _, match := range database.GetSchedule(playoffLevel) {
+		result, ok := results[match.Number]
+		series := bracket.getSeries(match.Series)
+		if !ok || series == nil || series.Winner != 0 {
+			continue
+		}
+		series.addResult(match, result, rules)
+		bracket.fillAlliances()
+	}
+	if final := bracket.getSeries("F"); final != nil {
+		bracket.Champion = final.Winner
+	}
+	return bracket, nil
+}
+
+// Schedules the next match of every series that is ready for one, this is called every time a playoff match is committed
+func Advance() (*Bracket, error) {
+	bracket, err := GetBracket()
+	if err != nil {
+		return nil, err
+	}
+	schedule := database.GetSchedule(playoffLevel)
+	alliances := make(map[int]database.Alliance)
+	for _, alliance := range database.GetAlliances() {
+		alliances[alliance.Number] = alliance
+	}
+	nextNumber := 1
+	waiting := make(map[string]bool)
+	for _, match := range schedule {
+		if match.Number >= nextNumber {
+			nextNumber = match.Number + 1
+		}
+		if !match.Played {
+			waiting[match.Series] = true
+		}
+	}
+	for _, series := range bracket.Series {
+		if series.Red == 0 || series.Blue == 0 || series.Winner != 0 || waiting[series.Name] {
+			continue
+		}
+		red, blue := alliances[series.Red], alliances[series.Blue]
+		match := database.ScheduledMatch{
+			Level:        playoffLevel,
+			Number:       nextNumber,
+			Series:       series.Name,
+			RedAlliance:  series.Red,
+			BlueAlliance: series.Blue,
+		}
+		match.Red1, match.Red2, match.Red3 = allianceTeams(red)
+		match.Blue1, match.Blue2, match.Blue3 = allianceTeams(blue)
+		if err := database.CreateScheduledMatch(&match); err != nil {
+			return nil, err
+		}
+		nextNumber++
+	}
+	events.Publish("bracketUpdated", bracket)
+	return bracket, nil
+}
+
+// The teams picked for an alliance in the order they were picked
+func pickedTeams(alliance database.Alliance) []int {
+	var teams []int
+	for _, teamNum := range []int{alliance.Captain, alliance.Pick1, alliance.Pick2, alliance.Pick3} {
+		if teamNum != 0 {
+			teams = append(teams, teamNum)
+		}
+	}
+	return teams
+}
+
+// The 3 teams an alliance plays with, a backup robot takes the place of the team it replaced.
+// A 4 team alliance plays it's first 3 teams and a 2 team alliance leaves the last station empty.
+func allianceTeams(alliance database.Alliance) (int, int, int) {
+	teams := make([]int, 0, 4)
+	for _, teamNum := range pickedTeams(alliance) {
+		if alliance.Backup != 0 && teamNum == alliance.BackupFor {
+			teamNum = alliance.Backup
+		}
+		teams = append(teams, teamNum)
+	}
+	for len(teams) < 3 {
+		teams = append(teams, 0)
+	}
+	return teams[0], teams[1], teams[2]
+}
+
+// Fills in every alliance that can be worked out from the seeds and finished series
+func (bracket *Bracket) fillAlliances() {
+	for _, series := range bracket.Series {
+		series.Red = bracket.resolve(series.red)
+		series.Blue = bracket.resolve(series.blue)
+		// In a best of 3 bracket the better seed is always red
+		if bracket.Format == BESTOFTHREE && series.Red != 0 && series.Blue != 0 && series.Blue < series.Red {
+			series.Red, series.Blue = series.Blue, series.Red
+		}
+	}
+}
+
+func (bracket *Bracket) resolve(source source) int {
+	if source.series == "" {
+		return source.alliance
+	}
+	series := bracket.getSeries(source.series)
+	if series == nil {
+		return 0
+	}
+	if source.loser {
+		return series.Loser
+	}
+	return series.Winner
+}
+
+func (bracket *Bracket) getSeries(name string) *Series {
+	for _, series := range bracket.Series {
+		if series.Name == name {
+			return series
+		}
+	}
+	return nil
+}
+
+// Counts a match towards the series, a tie goes to the playoff tiebreakers and if those are tied too the match is replayed.
+// Once the series is in overtime the next match won decides it, and if every overtime match is tied the better seed goes through.
+func (series *Series) addResult(match database.ScheduledMatch, result database.MatchResult, rules scoring.RankingRules) {
+	winner := matchWinner(match, result, rules)
+	if series.Overtime {
+		series.OvertimeMatches++
+	}
+
+	switch winner {
+	case 0:
+		series.Ties++
+	case series.Red:
+		series.RedWins++
+	case series.Blue:
+		series.BlueWins++
+	}
+	if series.RedWins >= series.WinsNeeded || (series.Overtime && winner == series.Red) {
+		series.Winner, series.Loser = series.Red, series.Blue
+	} else if series.BlueWins >= series.WinsNeeded || (series.Overtime && winner == series.Blue) {
+		series.Winner, series.Loser = series.Blue, series.Red
+	} else if series.OvertimeMatches >= maxOvertimeMatches {
+		// Alliance numbers are seeds, so the lower one is the better seed
+		if series.Red < series.Blue {
+			series.Winner, series.Loser = series.Red, series.Blue
+		} else {
+			series.Winner, series.Loser = series.Blue, series.Red
+		}
+	} else if series.RedWins+series.BlueWins+series.Ties >= series.WinsNeeded*2-1 {
+		series.Overtime = true
+	}
+}
+
+// The alliance that won a match, or 0 if it was tied even after the playoff tiebreakers
+func matchWinner(match database.ScheduledMatch, result database.MatchResult, rules scoring.RankingRules) int {
+	if result.RedScore > result.BlueScore {
+		return match.RedAlliance
+	} else if result.BlueScore > result.RedScore {
+		return match.BlueAlliance
+	}
+	var redScoringData, blueScoringData map[string]interface{}
+	json.Unmarshal([]byte(result.RedScoringData), &redScoringData)
+	json.Unmarshal([]byte(result.BlueScoringData), &blueScoringData)
+	redTiebreakers := rules.GetPlayoffTiebreakers(redScoringData)
+	blueTiebreakers := rules.GetPlayoffTiebreakers(blueScoringData)
+	for i := 0; i < len(redTiebreakers) && i < len(blueTiebreakers); i++ {
+		if redTiebreakers[i] > blueTiebreakers[i] {
+			return match.RedAlliance
+		} else if blueTiebreakers[i] > redTiebreakers[i] {
+			return match.BlueAlliance
+		}
+	}
+	return 0
+}
@@ -0,0 +1,237 @@
+package bracket
+
+import (
+	"testing"
+	"time"
+
+	"github.com/McMackety/nevermore/database"
+	"github.com/McMackety/nevermore/database/testutil"
+)
+
+// Saves finished alliances of 3 teams, alliance n has captain n*10 and picks n*10+1 onwards
+func saveTestAlliances(t *testing.T, allianceCount int) {
+	t.Helper()
+	var alliances []database.Alliance
+	for number := 1; number <= allianceCount; number++ {
+		alliances = append(alliances, database.Alliance{Number: number, Captain: number * 10, Pick1: number*10 + 1, Pick2: number*10 + 2})
+	}
+	saveAlliances(t, 3, alliances)
+}
+
+func saveAlliances(t *testing.T, teamsPerAlliance int, alliances []database.Alliance) {
+	t.Helper()
+	if err := database.SaveAllianceSelection(database.AllianceSelection{TeamsPerAlliance: teamsPerAlliance}, alliances, nil); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// Plays the next scheduled match, the better seed wins unless tie is set
+func playNextMatch(t *testing.T, tie bool) database.ScheduledMatch {
+	t.Helper()
+	match, err := database.GetNextScheduledMatch(playoffLevel)
+	if err != nil {
+		t.Fatal(err)
+	}
+	result := database.MatchResult{
+		Level:           playoffLevel,
+		MatchNumber:     match.Number,
+		RedScoringData:  "{}",
+		BlueScoringData: "{}",
+		Cards:           "{}",
+		CommittedAt:     time.Now(),
+	}
+	if !tie {
+		if match.RedAlliance < match.BlueAlliance {
+			result.RedScore = 10
+		} else {
+			result.BlueScore = 10
+		}
+	}
+	if err := database.CreateMatchResult(&result); err != nil {
+		t.Fatal(err)
+	}
+	if err := database.MarkScheduledMatchPlayed(playoffLevel, match.Number); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := Advance(); err != nil {
+		t.Fatal(err)
+	}
+	return match
+}
+
+func playUntilChampion(t *testing.T) *Bracket {
+	t.Helper()
+	for i := 0; i < 100; i++ {
+		bracket, err := GetBracket()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bracket.Champion != 0 {
+			return bracket
+		}
+		playNextMatch(t, false)
+	}
+	t.Fatal("the bracket never finished")
+	return nil
+}
+
+func TestBracketsFinish(t *testing.T) {
+	tests := []struct {
+		format        Format
+		allianceCount int
+	}{
+		{DOUBLEELIMINATION, 8},
+		{BESTOFTHREE, 8},
+		{BESTOFTHREE, 4},
+	}
+	for _, test := range tests {
+		testutil.UseTestDatabase(t)
+		saveTestAlliances(t, test.allianceCount)
+		if _, err := Generate(test.format); err != nil {
+			t.Fatal(err)
+		}
+		if bracket := playUntilChampion(t); bracket.Champion != 1 {
+			t.Errorf("expected alliance 1 to win the %s bracket with %d alliances, alliance %d won", test.format, test.allianceCount, bracket.Champion)
+		}
+	}
+}
+
+func TestTiedMatchIsReplayed(t *testing.T) {
+	testutil.UseTestDatabase(t)
+	saveTestAlliances(t, 4)
+	if _, err := Generate(BESTOFTHREE); err != nil {
+		t.Fatal(err)
+	}
+	tied := playNextMatch(t, true)
+	bracket, err := GetBracket()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if series := bracket.getSeries(tied.Series); series.Ties != 1 || series.RedWins != 0 || series.BlueWins != 0 {
+		t.Errorf("expected the tie to only count as a tie, got %+v", series)
+	}
+}
+
+func TestOvertimeIsWonByTheNextMatchWon(t *testing.T) {
+	testutil.UseTestDatabase(t)
+	saveTestAlliances(t, 4)
+	if _, err := Generate(BESTOFTHREE); err != nil {
+		t.Fatal(err)
+	}
+	// Both semifinals are scheduled together, so this ties 3 matches in each of them
+	for i := 0; i < 6; i++ {
+		playNextMatch(t, true)
+	}
+	won := playNextMatch(t, false)
+	bracket, err := GetBracket()
+	if err != nil {
+		t.Fatal(err)
+	}
+	series := bracket.getSeries(won.Series)
+	if !series.Overtime || series.OvertimeMatches != 1 {
+		t.Errorf("expected the series to be in it's first overtime match, got %+v", series)
+	}
+	if series.Winner != 1 && series.Winner != 2 {
+		t.Errorf("expected the better seed to win the series with 1 overtime win, got %+v", series)
+	}
+}
+
+func TestTiedOvertimeGoesToTheBetterSeed(t *testing.T) {
+	testutil.UseTestDatabase(t)
+	saveTestAlliances(t, 8)
+	if _, err := Generate(DOUBLEELIMINATION); err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 1000; i++ {
+		bracket, err := GetBracket()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if bracket.Champion != 0 {
+			if bracket.Champion != 1 {
+				t.Errorf("expected alliance 1 to win when every match is tied, alliance %d won", bracket.Champion)
+			}
+			if final := bracket.getSeries("F"); final.OvertimeMatches != maxOvertimeMatches || final.RedWins != 0 || final.BlueWins != 0 {
+				t.Errorf("expected the finals to go through every overtime match, got %+v", final)
+			}
+			return
+		}
+		playNextMatch(t, true)
+	}
+	t.Fatal("the bracket never finished")
+}
+
+func TestRegenerateIgnoresOldResults(t *testing.T) {
+	testutil.UseTestDatabase(t)
+	saveTestAlliances(t, 4)
+	if _, err := Generate(BESTOFTHREE); err != nil {
+		t.Fatal(err)
+	}
+	playUntilChampion(t)
+
+	if _, err := Generate(BESTOFTHREE); err != nil {
+		t.Fatal(err)
+	}
+	bracket, err := GetBracket()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bracket.Champion != 0 {
+		t.Errorf("expected a new bracket not to have a champion, alliance %d won", bracket.Champion)
+	}
+	for _, series := range bracket.Series {
+		if series.RedWins != 0 || series.BlueWins != 0 || series.Winner != 0 {
+			t.Errorf("expected series %s to start over, got %+v", series.Name, series)
+		}
+	}
+	if len(database.GetMatchResults(playoffLevel)) != 0 {
+		t.Error("expected the old playoff results to be deleted")
+	}
+}
+
+func TestAllianceSizes(t *testing.T) {
+	tests := []struct {
+		name             string
+		teamsPerAlliance int
+		alliance         database.Alliance
+		expected         [3]int
+	}{
+		{"2 teams", 2, database.Alliance{Captain: 10, Pick1: 11}, [3]int{10, 11, 0}},
+		{"3 teams", 3, database.Alliance{Captain: 10, Pick1: 11, Pick2: 12}, [3]int{10, 11, 12}},
+		{"4 teams", 4, database.Alliance{Captain: 10, Pick1: 11, Pick2: 12, Pick3: 13}, [3]int{10, 11, 12}},
+		{"backup for the captain", 3, database.Alliance{Captain: 10, Pick1: 11, Pick2: 12, Backup: 99, BackupFor: 10}, [3]int{99, 11, 12}},
+		{"backup for the first pick", 2, database.Alliance{Captain: 10, Pick1: 11, Backup: 99, BackupFor: 11}, [3]int{10, 99, 0}},
+	}
+	for _, test := range tests {
+		testutil.UseTestDatabase(t)
+		var alliances []database.Alliance
+		for number := 1; number <= 4; number++ {
+			alliance := test.alliance
+			alliance.Number = number
+			alliances = append(alliances, alliance)
+		}
+		saveAlliances(t, test.teamsPerAlliance, alliances)
+		if _, err := Generate(BESTOFTHREE); err != nil {
+			t.Fatalf("%s: %v", test.name, err)
+		}
+		match, err := database.GetNextScheduledMatch(playoffLevel)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if red := [3]int{match.Red1, match.Red2, match.Red3}; red != test.expected {
+			t.Errorf("%s: expected red to be %v, got %v", test.name, test.expected, red)
+		}
+	}
+}
+
+func TestUnfinishedSelection(t *testing.T) {
+	testutil.UseTestDatabase(t)
+	var alliances []database.Alliance
+	for number := 1; number <= 4; number++ {
+		alliances = append(alliances, database.Alliance{Number: number, Captain: number * 10, Pick1: number*10 + 1, Pick2: number*10 + 2})
+	}
+	saveAlliances(t, 4, alliances)
+	if _, err := Generate(BESTOFTHREE); err == nil {
+		t.Error("expected 4 team alliances with only 3 teams not to make a bracket")
+	}
+}
@@ -0,0 +1,122 @@
+package bracket
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Format is how the playoffs are played
+type Format string
+
+// The playoff formats
+const (
+	// BESTOFTHREE is the classic elimination bracket, every series is the first to win 2 matches
+	BESTOFTHREE Format = "bestOfThree"
+	// DOUBLEELIMINATION is the 8 alliance double elimination bracket, every match decides it's series except the best of 3 finals
+	DOUBLEELIMINATION Format = "doubleElimination"
+)
+
+// Parses a format from it's name, like "doubleElimination"
+func ParseFormat(name string) (Format, error) {
+	for _, format := range []Format{BESTOFTHREE, DOUBLEELIMINATION} {
+		if strings.EqualFold(string(format), name) {
+			return format, nil
+		}
+	}
+	return "", fmt.Errorf("unknown playoff format %s, it has to be %s or %s", name, BESTOFTHREE, DOUBLEELIMINATION)
+}
+
+// source is where an alliance in a series comes from, either a seed or the winner or loser of another series
+type source struct {
+	alliance int
+	series   string
+	loser    bool
+}
+
+func seed(alliance int) source {
+	return source{alliance: alliance}
+}
+
+func winnerOf(series string) source {
+	return source{series: series}
+}
+
+func loserOf(series string) source {
+	return source{series: series, loser: true}
+}
+
+// String is how the source is shown before the alliance is known, like "A1", "W3" or "L3"
+func (source source) String() string {
+	if source.series == "" {
+		return fmt.Sprintf("A%d", source.alliance)
+	}
+	if source.loser {
+		return "L" + source.series
+	}
+	return "W" + source.series
+}
+
+// seriesTemplate is a series before any matches are played
+type seriesTemplate struct {
+	name       string
+	red        source
+	blue       source
+	winsNeeded int
+}
+
+// Gets the series in a bracket, in the order their first matches are played
+func templates(format Format, allianceCount int) ([]seriesTemplate, error) {
+	switch format {
+	case BESTOFTHREE:
+		switch allianceCount {
+		case 2:
+			return []seriesTemplate{
+				{"F", seed(1), seed(2), 2},
+			}, nil
+		case 4:
+			return []seriesTemplate{
+				{"SF1", seed(1), seed(4), 2},
+				{"SF2", seed(2), seed(3), 2},
+				{"F", winnerOf("SF1"), winnerOf("SF2"), 2},
+			}, nil
+		case 8:
+			return []seriesTemplate{
+				{"QF1", seed(1), seed(8), 2},
+				{"QF2", seed(4), seed(5), 2},
+				{"QF3", seed(2), seed(7), 2},
+				{"QF4", seed(3), seed(6), 2},
+				{"SF1", winnerOf("QF1"), winnerOf("QF2"), 2},
+				{"SF2", winnerOf("QF3"), winnerOf("QF4"), 2},
+				{"F", winnerOf("SF1"), winnerOf("SF2"), 2},
+			}, nil
+		}
+		return nil, fmt.Errorf("a best of 3 bracket needs 2, 4 or 8 alliances, not %d", allianceCount)
+	case DOUBLEELIMINATION:
+		if allianceCount != 8 {
+			return nil, fmt.Errorf("a double elimination bracket needs 8 alliances, not %d", allianceCount)
+		}
+		return []seriesTemplate{
+			// Round 1
+			{"M1", seed(1), seed(8), 1},
+			{"M2", seed(4), seed(5), 1},
+			{"M3", seed(2), seed(7), 1},
+			{"M4", seed(3), seed(6), 1},
+			// Round 2
+			{"M5", loserOf("M1"), loserOf("M2"), 1},
+			{"M6", loserOf("M3"), loserOf("M4"), 1},
+			{"M7", winnerOf("M1"), winnerOf("M2"), 1},
+			{"M8", winnerOf("M3"), winnerOf("M4"), 1},
+			// Round 3
+			{"M9", loserOf("M7"), winnerOf("M6"), 1},
+			{"M10", loserOf("M8"), winnerOf("M5"), 1},
+			{"M11", winnerOf("M7"), winnerOf("M8"), 1},
+			// Round 4
+			{"M12", winnerOf("M10"), winnerOf("M9"), 1},
+			// Round 5
+			{"M13", loserOf("M11"), winnerOf("M12"), 1},
+			// Finals
+			{"F", winnerOf("M11"), winnerOf("M13"), 2},
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown playoff format %s", format)
+}
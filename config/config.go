@@ -4,7 +4,9 @@ import (
 	"encoding/json"
 	"io/ioutil"
 	"log"
+	"net"
 	"os"
+	"strconv"
 )
 
 // DefaultConfig is the config loaded at the start of the program
@@ -14,6 +16,9 @@ var DefaultConfig Config
 type Config struct {
 	WebSocketListenAddress string `json:"websocketListenAddress"`
 	Database DatabaseConfig `json:"database"`
+	Network NetworkConfig `json:"network"`
+	// TimingProfiles are keyed by the name of the tournament level, like "PRACTICE"
+	TimingProfiles map[string]TimingProfileConfig `json:"timingProfiles"`
 }
 
 // DatabaseConfig is the struct defining the database in the
@@ -22,6 +27,64 @@ type DatabaseConfig struct {
 	Address string `json:"address"`
 }
 
+// NetworkConfig is the struct defining where the FMS listens for driverstations
+type NetworkConfig struct {
+	// DevelopmentMode lets the FMS run on a machine without the field network, binding to loopback by default
+	DevelopmentMode bool `json:"developmentMode"`
+	BindAddress string `json:"bindAddress"`
+	TCPPort int `json:"tcpPort"`
+	UDPPort int `json:"udpPort"`
+	DriverStationUDPPort int `json:"driverStationUDPPort"`
+	// ControlPacketInterval is how often robots are sent a control packet in milliseconds,
+	// packets are also sent right away whenever a robot is enabled, disabled or e-stopped
+	ControlPacketInterval int `json:"controlPacketInterval"`
+	// StationSubnets maps each alliance station, like "RED1", to the subnet of it's ethernet port.
+	// Stations left out use the subnet of the team assigned to them (10.TE.AM.0/24), except in development mode.
+	// A driverstation connecting from another station's subnet is marked BAD and never enabled.
+	StationSubnets map[string]string `json:"stationSubnets"`
+}
+
+// TimingProfileConfig is the struct defining how long each period of a match lasts, in seconds.
+// A period that's left out is nil so it can take the default length.
+type TimingProfileConfig struct {
+	AutoLength *int `json:"autoLength"`
+	TransitionLength *int `json:"transitionLength"`
+	TeleopLength *int `json:"teleopLength"`
+	EndgameLength *int `json:"endgameLength"`
+}
+
+// Fills in anything left out of the network config with the FRC defaults
+func (network *NetworkConfig) setDefaults() {
+	if network.BindAddress == "" {
+		if network.DevelopmentMode {
+			network.BindAddress = "127.0.0.1"
+		} else {
+			network.BindAddress = "10.0.100.5"
+		}
+	}
+	if network.TCPPort == 0 {
+		network.TCPPort = 1750
+	}
+	if network.UDPPort == 0 {
+		network.UDPPort = 1160
+	}
+	if network.DriverStationUDPPort == 0 {
+		network.DriverStationUDPPort = 1121
+	}
+	if network.ControlPacketInterval <= 0 {
+		network.ControlPacketInterval = 250
+	}
+}
+
+// TCPAddress is the address the FMS listens for driverstation TCP connections on
+func (network NetworkConfig) TCPAddress() string {
+	return net.JoinHostPort(network.BindAddress, strconv.Itoa(network.TCPPort))
+}
+
+// UDPAddress is the address the FMS listens for driverstation UDP packets on
+func (network NetworkConfig) UDPAddress() string {
+	return net.JoinHostPort(network.BindAddress, strconv.Itoa(network.UDPPort))
+}
 
 func LoadConfig() {
 	// Load the jsonFile from disk
@@ -41,6 +104,7 @@ func LoadConfig() {
 	if err != nil {
 		log.Panic("Couldn't decipher config.json, check if it is valid JSON.")
 	}
+	DefaultConfig.Network.setDefaults()
 
 	log.Println("Successfully loaded config.json!")
 }
\ No newline at end of file
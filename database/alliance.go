@@ -0,0 +1,114 @@
+package database
+
+import (
+	"errors"
+	"github.com/jinzhu/gorm"
+)
+
+// Alliance is a playoff alliance picked during alliance selection
+type Alliance struct {
+	gorm.Model
+	Number    int `gorm:"unique_index"`
+	Captain   int
+	Pick1     int
+	Pick2     int
+	Pick3     int // Only used when alliances have 4 teams
+	Backup    int // A backup robot that replaced one of the teams
+	BackupFor int // The team the backup robot replaced
+}
+
+// DeclinedTeam is a team that declined an invitation during alliance selection, it can't be picked again
+type DeclinedTeam struct {
+	gorm.Model
+	TeamNumber int `gorm:"unique_index"`
+}
+
+// AllianceSelection is how far alliance selection has got, only the latest one is kept
+type AllianceSelection struct {
+	gorm.Model
+	TeamsPerAlliance int
+	Rankings         string // JSON encoded team numbers in the order they ranked when selection started
+	Round            int    // 0 once every pick has been made
+	PickingAlliance  int    // 0 once every pick has been made
+	InvitedAlliance  int    // 0 when nobody has been invited
+	InvitedTeam      int
+}
+
+// Replaces the saved alliance selection, nothing is changed if any of it can't be saved
+func SaveAllianceSelection(selection AllianceSelection, alliances []Alliance, declinedTeams []int) error {
+	transaction := Database.Begin()
+	if err := transaction.Unscoped().Delete(&AllianceSelection{}).Error; err != nil {
+		transaction.Rollback()
+		return err
+	}
+	if err := transaction.Create(&selection).Error; err != nil {
+		transaction.Rollback()
+		return err
+	}
+	if err := transaction.Unscoped().Delete(&Alliance{}).Error; err != nil {
+		transaction.Rollback()
+		return err
+	}
+	if err := transaction.Unscoped().Delete(&DeclinedTeam{}).Error; err != nil {
+		transaction.Rollback()
+		return err
+	}
+	for i := range alliances {
+		if err := transaction.Create(&alliances[i]).Error; err != nil {
+			transaction.Rollback()
+			return err
+		}
+	}
+	for _, teamNum := range declinedTeams {
+		if err := transaction.Create(&DeclinedTeam{TeamNumber: teamNum}).Error; err != nil {
+			transaction.Rollback()
+			return err
+		}
+	}
+	return transaction.Commit().Error
+}
+
+// Gets how far the saved alliance selection got
+func GetAllianceSelection() (selection AllianceSelection, err error) {
+	var selectionFromDatabase AllianceSelection
+	if err := Database.Order("id desc").First(&selectionFromDatabase).Error; err != nil {
+		return selectionFromDatabase, errors.New("alliance selection hasn't started")
+	}
+	return selectionFromDatabase, nil
+}
+
+func GetAlliances() []Alliance {
+	var alliances []Alliance
+	Database.Order("number").Find(&alliances)
+	return alliances
+}
+
+func GetDeclinedTeams() []int {
+	var declinedTeams []DeclinedTeam
+	Database.Order("id").Find(&declinedTeams)
+	var teams []int
+	for _, declinedTeam := range declinedTeams {
+		teams = append(teams, declinedTeam.TeamNumber)
+	}
+	return teams
+}
+
+// PlayoffBracket is the format of the playoffs, the bracket itself is worked out from the alliances and match results
+type PlayoffBracket struct {
+	gorm.Model
+	Format        string
+	AllianceCount int
+}
+
+func CreatePlayoffBracket(bracket *PlayoffBracket) error {
+	return Database.Create(bracket).Error
+}
+
+// Gets the bracket being played, which is the last one created
+func GetCurrentPlayoffBracket() (bracket PlayoffBracket, err error) {
+	var bracketFromDatabase PlayoffBracket
+	if err := Database.Order("id desc").First(&bracketFromDatabase).Error; err != nil {
+		return bracketFromDatabase, errors.New("the playoff bracket hasn't been made yet")
+	}
+	return bracketFromDatabase, nil
+}
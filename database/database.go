@@ -8,14 +8,16 @@ import (
 	_ "github.com/jinzhu/gorm/dialects/sqlite"
 )
 
-var Database gorm.DB
+var Database *gorm.DB
 
 func InitDatabase() {
-	Database, err := gorm.Open(config.DefaultConfig.Database.Type, config.DefaultConfig.Database.Address)
+	var err error
+	Database, err = gorm.Open(config.DefaultConfig.Database.Type, config.DefaultConfig.Database.Address)
 	if err != nil {
 		panic("failed to connect database: " + err.Error())
 	}
 
-	Database.AutoMigrate(&User{})
+	Database.AutoMigrate(&User{}, &MatchResult{}, &Team{}, &Event{}, &ScheduledMatch{}, &RankingSnapshot{}, &AllianceSelection{}, &Alliance{}, &DeclinedTeam{}, &PlayoffBracket{})
+	hashPlainTextPins()
 
 }
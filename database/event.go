@@ -0,0 +1,55 @@
+package database
+
+import (
+	"errors"
+	"github.com/jinzhu/gorm"
+	"time"
+)
+
+// Event is a competition, the one selected last is the one being run
+type Event struct {
+	gorm.Model
+	Code       string    `gorm:"unique_index" json:"code"`
+	Name       string    `json:"name"`
+	StartDate  time.Time `json:"startDate"`
+	EndDate    time.Time `json:"endDate"`
+	SelectedAt time.Time `json:"selectedAt"`
+}
+
+// Creates an event and selects it
+func CreateEvent(event *Event) error {
+	if event.Code == "" {
+		return errors.New("the event needs a code")
+	}
+	event.SelectedAt = time.Now()
+	return Database.Create(event).Error
+}
+
+// Selects an event that was already created, making it the one being run
+func SelectEvent(code string) (event Event, err error) {
+	var eventFromDatabase Event
+	if err := Database.Where("code = ?", code).First(&eventFromDatabase).Error; err != nil {
+		return eventFromDatabase, errors.New("couldn't find event")
+	}
+	eventFromDatabase.SelectedAt = time.Now()
+	if err := Database.Save(&eventFromDatabase).Error; err != nil {
+		return eventFromDatabase, err
+	}
+	return eventFromDatabase, nil
+}
+
+// Gets every event, newest first
+func GetAllEvents() []Event {
+	var events []Event
+	Database.Order("id desc").Find(&events)
+	return events
+}
+
+// Gets the event being run, which is the last one created or selected
+func GetCurrentEvent() (event Event, err error) {
+	var eventFromDatabase Event
+	if err := Database.Order("selected_at desc, id desc").First(&eventFromDatabase).Error; err != nil {
+		return eventFromDatabase, errors.New("there isn't an event yet")
+	}
+	return eventFromDatabase, nil
+}
@@ -0,0 +1,35 @@
+package database_test
+
+import (
+	"testing"
+
+	"github.com/McMackety/nevermore/database"
+	"github.com/McMackety/nevermore/database/testutil"
+)
+
+func TestSelectingEvents(t *testing.T) {
+	testutil.UseTestDatabase(t)
+	if _, err := database.GetCurrentEvent(); err == nil {
+		t.Fatal("expected there to be no event before one is created")
+	}
+	for _, code := range []string{"2020ONTOR", "2020ONWAT"} {
+		if err := database.CreateEvent(&database.Event{Code: code}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := database.CreateEvent(&database.Event{Code: "2020ONTOR"}); err == nil {
+		t.Error("expected a second event with the same code to be rejected")
+	}
+	if event, err := database.GetCurrentEvent(); err != nil || event.Code != "2020ONWAT" {
+		t.Errorf("expected the newest event to be current, got %s (%v)", event.Code, err)
+	}
+	if _, err := database.SelectEvent("2020ONTOR"); err != nil {
+		t.Fatal(err)
+	}
+	if event, err := database.GetCurrentEvent(); err != nil || event.Code != "2020ONTOR" {
+		t.Errorf("expected the selected event to be current, got %s (%v)", event.Code, err)
+	}
+	if _, err := database.SelectEvent("2020MISSING"); err == nil {
+		t.Error("expected selecting an event that doesn't exist to fail")
+	}
+}
@@ -0,0 +1,63 @@
+package database
+
+import (
+	"errors"
+	"github.com/jinzhu/gorm"
+	"time"
+)
+
+// MatchResult is the committed result of a match, a replayed match has a result for every time it was played
+type MatchResult struct {
+	gorm.Model
+	MatchNumber     int       `gorm:"index:idx_result_level_number" json:"matchNum"`
+	Level           int       `gorm:"index:idx_result_level_number" json:"matchLevel"` // An enums.Level
+	Red1            int       `json:"red1"`
+	Red2            int       `json:"red2"`
+	Red3            int       `json:"red3"`
+	Blue1           int       `json:"blue1"`
+	Blue2           int       `json:"blue2"`
+	Blue3           int       `json:"blue3"`
+	RedScore        int       `json:"redScore"`
+	BlueScore       int       `json:"blueScore"`
+	RedFouls        int       `json:"redFouls"` // Fouls committed by red
+	RedTechFouls    int       `json:"redTechFouls"`
+	BlueFouls       int       `json:"blueFouls"` // Fouls committed by blue
+	BlueTechFouls   int       `json:"blueTechFouls"`
+	RedScoringData  string    `json:"redScoringData"`  // JSON encoded scoring data from the scorer
+	BlueScoringData string    `json:"blueScoringData"` // JSON encoded scoring data from the scorer
+	Cards           string    `json:"cards"`           // JSON encoded map of alliance station to card
+	StartedAt       time.Time `json:"startedAt"`
+	EndedAt         time.Time `json:"endedAt"`
+	CommittedAt     time.Time `json:"committedAt"`
+}
+
+func CreateMatchResult(result *MatchResult) error {
+	return Database.Create(result).Error
+}
+
+func GetAllMatchResults() []MatchResult {
+	var results []MatchResult
+	Database.Order("committed_at").Find(&results)
+	return results
+}
+
+// Gets every result for a level, oldest first
+func GetMatchResults(level int) []MatchResult {
+	var results []MatchResult
+	Database.Where("level = ?", level).Order("committed_at").Find(&results)
+	return results
+}
+
+// Gets the result of the last time a match was played
+func GetMatchResult(level int, matchNum int) (result MatchResult, err error) {
+	var resultFromDatabase MatchResult
+	if err := Database.Where("level = ? AND match_number = ?", level, matchNum).Order("committed_at desc").First(&resultFromDatabase).Error; err != nil {
+		return resultFromDatabase, errors.New("couldn't find a result for that match")
+	}
+	return resultFromDatabase, nil
+}
+
+// Deletes every result for a level, they're only soft deleted so they can still be found in the database
+func DeleteMatchResults(level int) error {
+	return Database.Where("level = ?", level).Delete(&MatchResult{}).Error
+}
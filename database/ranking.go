@@ -0,0 +1,35 @@
+package database
+
+import (
+	"errors"
+	"github.com/jinzhu/gorm"
+	"time"
+)
+
+// RankingSnapshot is the rankings as they were right after a match was committed
+type RankingSnapshot struct {
+	gorm.Model
+	Level       int    // An enums.Level
+	MatchNumber int    // The match that was just committed
+	Rankings    string // JSON encoded rankings
+	TakenAt     time.Time
+}
+
+func CreateRankingSnapshot(snapshot *RankingSnapshot) error {
+	return Database.Create(snapshot).Error
+}
+
+// Gets every snapshot for a level, oldest first
+func GetRankingSnapshots(level int) []RankingSnapshot {
+	var snapshots []RankingSnapshot
+	Database.Where("level = ?", level).Order("taken_at").Find(&snapshots)
+	return snapshots
+}
+
+func GetLatestRankingSnapshot(level int) (snapshot RankingSnapshot, err error) {
+	var snapshotFromDatabase RankingSnapshot
+	if err := Database.Where("level = ?", level).Order("taken_at desc").First(&snapshotFromDatabase).Error; err != nil {
+		return snapshotFromDatabase, errors.New("there aren't any rankings yet")
+	}
+	return snapshotFromDatabase, nil
+}
@@ -0,0 +1,73 @@
+package database
+
+import (
+	"errors"
+	"github.com/jinzhu/gorm"
+	"time"
+)
+
+// ScheduledMatch is a single match in the schedule, a surrogate's result doesn't count towards it's rankings
+type ScheduledMatch struct {
+	gorm.Model
+	EventID        uint      `json:"eventId"`
+	Level          int       `gorm:"index:idx_level_number" json:"matchLevel"` // An enums.Level
+	Number         int       `gorm:"index:idx_level_number" json:"matchNum"`
+	Time           time.Time `json:"time"`
+	Red1           int       `json:"red1"`
+	Red2           int       `json:"red2"`
+	Red3           int       `json:"red3"`
+	Blue1          int       `json:"blue1"`
+	Blue2          int       `json:"blue2"`
+	Blue3          int       `json:"blue3"`
+	Red1Surrogate  bool      `json:"red1Surrogate"`
+	Red2Surrogate  bool      `json:"red2Surrogate"`
+	Red3Surrogate  bool      `json:"red3Surrogate"`
+	Blue1Surrogate bool      `json:"blue1Surrogate"`
+	Blue2Surrogate bool      `json:"blue2Surrogate"`
+	Blue3Surrogate bool      `json:"blue3Surrogate"`
+	Played         bool      `json:"played"`
+	Series         string    `json:"series"`       // The playoff series, like "QF1" or "M5"
+	RedAlliance    int       `json:"redAlliance"`  // The playoff alliance number
+	BlueAlliance   int       `json:"blueAlliance"` // The playoff alliance number
+}
+
+// Replaces the schedule for a level, the old matches are only deleted if all the new ones are saved
+func ReplaceSchedule(level int, matches []ScheduledMatch) error {
+	transaction := Database.Begin()
+	if err := transaction.Unscoped().Where("level = ?", level).Delete(&ScheduledMatch{}).Error; err != nil {
+		transaction.Rollback()
+		return err
+	}
+	for i := range matches {
+		matches[i].Level = level
+		if err := transaction.Create(&matches[i]).Error; err != nil {
+			transaction.Rollback()
+			return err
+		}
+	}
+	return transaction.Commit().Error
+}
+
+func CreateScheduledMatch(match *ScheduledMatch) error {
+	return Database.Create(match).Error
+}
+
+func GetSchedule(level int) []ScheduledMatch {
+	var matches []ScheduledMatch
+	Database.Where("level = ?", level).Order("number").Find(&matches)
+	return matches
+}
+
+// Gets the first match in the schedule for a level that hasn't been played
+func GetNextScheduledMatch(level int) (match ScheduledMatch, err error) {
+	var matchFromDatabase ScheduledMatch
+	if err := Database.Where("level = ? AND played = ?", level, false).Order("number").First(&matchFromDatabase).Error; err != nil {
+		return matchFromDatabase, errors.New("there aren't any matches left in the schedule")
+	}
+	return matchFromDatabase, nil
+}
+
+// Marks a match as played, this does nothing if the match isn't in the schedule
+func MarkScheduledMatchPlayed(level int, number int) error {
+	return Database.Model(&ScheduledMatch{}).Where("level = ? AND number = ?", level, number).Update("played", true).Error
+}
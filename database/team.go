@@ -0,0 +1,58 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"github.com/jinzhu/gorm"
+)
+
+// Team is a team registered for the event
+type Team struct {
+	gorm.Model
+	Number   int    `gorm:"unique_index" json:"teamNum"`
+	Name     string `json:"name"`
+	Nickname string `json:"nickname"`
+	Location string `json:"location"`
+}
+
+func CreateTeam(team *Team) error {
+	if team.Number <= 0 {
+		return errors.New("the team number has to be positive")
+	}
+	return Database.Create(team).Error
+}
+
+// Adds a list of teams, updating any that are already registered. If any of them can't be saved none of them are
+func SaveTeams(teams []Team) error {
+	transaction := Database.Begin()
+	for i := range teams {
+		var existing Team
+		if err := transaction.Where("number = ?", teams[i].Number).First(&existing).Error; err == nil {
+			teams[i].Model = existing.Model
+		}
+		if err := transaction.Save(&teams[i]).Error; err != nil {
+			transaction.Rollback()
+			return fmt.Errorf("couldn't save team %d: %s", teams[i].Number, err.Error())
+		}
+	}
+	return transaction.Commit().Error
+}
+
+func GetAllTeams() []Team {
+	var teams []Team
+	Database.Order("number").Find(&teams)
+	return teams
+}
+
+func GetTeamByNumber(teamNum int) (team Team, err error) {
+	var teamFromDatabase Team
+	if err := Database.Where("number = ?", teamNum).First(&teamFromDatabase).Error; err != nil {
+		return teamFromDatabase, errors.New("couldn't find team")
+	}
+	return teamFromDatabase, nil
+}
+
+func DeleteTeam(teamNum int) error {
+	// Deleted for real so the team number can be used again
+	return Database.Unscoped().Where("number = ?", teamNum).Delete(&Team{}).Error
+}
@@ -0,0 +1,18 @@
+// Package testutil has the fixtures shared by the tests of every package that uses the database
+package testutil
+
+import (
+	"testing"
+
+	"github.com/McMackety/nevermore/config"
+	"github.com/McMackety/nevermore/database"
+)
+
+// Points the database at a new in memory sqlite database
+func UseTestDatabase(t testing.TB) {
+	t.Helper()
+	config.DefaultConfig.Database = config.DatabaseConfig{Type: "sqlite3", Address: ":memory:"}
+	database.InitDatabase()
+	// Every connection to :memory: is it's own database
+	database.Database.DB().SetMaxOpenConns(1)
+}
@@ -3,6 +3,8 @@ package database
 import (
 	"errors"
 	"github.com/jinzhu/gorm"
+	"golang.org/x/crypto/bcrypt"
+	"strings"
 )
 
 type UserType int
@@ -15,50 +17,125 @@ const (
 	SCORER
 )
 
+func (userType UserType) String() string {
+	switch userType {
+	case ADMIN:
+		return "ADMIN"
+	case FTA:
+		return "FTA"
+	case HEADREFEREE:
+		return "HEADREFEREE"
+	case REFEREE:
+		return "REFEREE"
+	case SCORER:
+		return "SCORER"
+	}
+	return "UNKNOWN"
+}
+
+// Parses a user type from it's name, like "FTA"
+func ParseUserType(name string) (UserType, error) {
+	for userType := ADMIN; userType <= SCORER; userType++ {
+		if strings.EqualFold(userType.String(), name) {
+			return userType, nil
+		}
+	}
+	return 0, errors.New("unknown user type " + name)
+}
+
 type User struct {
 	gorm.Model
-	Username string
+	Username string `gorm:"unique_index"`
 	UserType UserType
-	Pin      string
+	Pin      string `json:"-"` // A bcrypt hash of the user's PIN, never the PIN itself
 }
 
 func GetAllUsers() []User {
 	var users []User
-	Database.Select("id, createdAt, updatedAt, username, userType").Find(&users)
+	Database.Select("id, created_at, updated_at, username, user_type").Find(&users)
 	return users
 }
 
 func GetUserByID(id uint) (user User, err error) {
 	var userFromDatabase User
-	if err := Database.Where("id = ?", id).Select("id, createdAt, updatedAt, username, userType").First(&userFromDatabase).Error; err != nil {
-		return userFromDatabase, nil
+	if err := Database.Where("id = ?", id).Select("id, created_at, updated_at, username, user_type").First(&userFromDatabase).Error; err != nil {
+		return userFromDatabase, errors.New("couldn't find user")
 	}
-	return userFromDatabase, errors.New("couldn't find user")
+	return userFromDatabase, nil
 }
 
 func GetUserByUsername(username string) (user User, err error) {
 	var userFromDatabase User
-	if err := Database.First(&userFromDatabase, "username = ?", username).Select("id, createdAt, updatedAt, username, userType").Error; err != nil {
-		return userFromDatabase, nil
+	if err := Database.Where("username = ?", username).Select("id, created_at, updated_at, username, user_type").First(&userFromDatabase).Error; err != nil {
+		return userFromDatabase, errors.New("couldn't find user")
 	}
-	return userFromDatabase, errors.New("couldn't find user")
+	return userFromDatabase, nil
 }
 
 func CheckUserPIN(username string, pin string) bool {
 	var userFromDatabase User
-	if err := Database.Where("username = ?", username).Select("id, createdAt, updatedAt, username, userType").First(&userFromDatabase).Error; err != nil {
+	if err := Database.Where("username = ?", username).First(&userFromDatabase).Error; err != nil {
 		return false
 	}
-	if userFromDatabase.Pin == pin {
-		return true
+	return bcrypt.CompareHashAndPassword([]byte(userFromDatabase.Pin), []byte(pin)) == nil
+}
+
+func CreateUser(username string, userType UserType, pin string) error {
+	hashedPin, err := hashPin(pin)
+	if err != nil {
+		return err
 	}
-	return false
+	return Database.Create(&User{Username: username, UserType: userType, Pin: hashedPin}).Error
 }
 
-func CreateUser(username string, userType UserType, pin string) {
-	Database.Create(&User{Username: username, UserType: userType, Pin: pin})
+// Changes a user's PIN
+func (user *User) SetPIN(pin string) error {
+	hashedPin, err := hashPin(pin)
+	if err != nil {
+		return err
+	}
+	return Database.Model(user).Update("pin", hashedPin).Error
 }
 
 func (user *User) Update(updates map[string]interface{}) {
+	// PINs have to go through SetPIN so they get hashed
+	delete(updates, "pin")
+	delete(updates, "Pin")
 	Database.Model(user).Updates(updates)
 }
+
+func DeleteUser(username string) error {
+	result := Database.Unscoped().Where("username = ?", username).Delete(&User{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return errors.New("couldn't find user")
+	}
+	return nil
+}
+
+func hashPin(pin string) (string, error) {
+	if pin == "" {
+		return "", errors.New("the pin can't be empty")
+	}
+	hashedPin, err := bcrypt.GenerateFromPassword([]byte(pin), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+	return string(hashedPin), nil
+}
+
+// Hashes any PINs that were stored in plain text before they were hashed
+func hashPlainTextPins() {
+	var users []User
+	Database.Find(&users)
+	for _, user := range users {
+		if user.Pin == "" || strings.HasPrefix(user.Pin, "$2") {
+			continue
+		}
+		if err := user.SetPIN(user.Pin); err != nil {
+			panic("failed to hash the pin for " + user.Username + ": " + err.Error())
+		}
+	}
+}
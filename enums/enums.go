@@ -0,0 +1,63 @@
+package enums
+
+import (
+	"errors"
+	"strings"
+)
+
+// Level is the tournament level, it's saved with every scheduled match and result.
+type Level int
+
+// The different tournament levels.
+const (
+	MATCHTEST Level = iota
+	PRACTICE
+	QUALIFICATION
+	PLAYOFF
+)
+
+func (level Level) String() string {
+	switch level {
+	case MATCHTEST:
+		return "MATCHTEST"
+	case PRACTICE:
+		return "PRACTICE"
+	case QUALIFICATION:
+		return "QUALIFICATION"
+	case PLAYOFF:
+		return "PLAYOFF"
+	}
+	return "UNKNOWN"
+}
+
+// Parses a tournament level from it's name, like "QUALIFICATION"
+func ParseLevel(name string) (Level, error) {
+	for level := MATCHTEST; level <= PLAYOFF; level++ {
+		if strings.EqualFold(level.String(), name) {
+			return level, nil
+		}
+	}
+	return 0, errors.New("unknown tournament level " + name)
+}
+
+// Card is a card given to a team by the referees
+type Card int
+
+// The different cards
+const (
+	NOCARD Card = iota
+	YELLOWCARD
+	REDCARD
+)
+
+func (card Card) String() string {
+	switch card {
+	case NOCARD:
+		return "NOCARD"
+	case YELLOWCARD:
+		return "YELLOWCARD"
+	case REDCARD:
+		return "REDCARD"
+	}
+	return "UNKNOWN"
+}
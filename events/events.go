@@ -0,0 +1,58 @@
+package events
+
+import (
+	"encoding/json"
+	"log"
+	"sync"
+)
+
+// Event is a single named update sent to everything listening, Data is already encoded as JSON
+type Event struct {
+	Name string          `json:"event"`
+	Data json.RawMessage `json:"data"`
+}
+
+// How many events a subscriber can fall behind before it starts missing them
+const subscriberBufferSize = 256
+
+var subscribersMutex sync.Mutex
+var subscribers = make(map[chan Event]bool)
+
+// Subscribe returns a channel that receives every event published after this call
+func Subscribe() chan Event {
+	subscribersMutex.Lock()
+	defer subscribersMutex.Unlock()
+	subscriber := make(chan Event, subscriberBufferSize)
+	subscribers[subscriber] = true
+	return subscriber
+}
+
+// Unsubscribe stops sending events to a channel and closes it
+func Unsubscribe(subscriber chan Event) {
+	subscribersMutex.Lock()
+	defer subscribersMutex.Unlock()
+	if _, ok := subscribers[subscriber]; ok {
+		delete(subscribers, subscriber)
+		close(subscriber)
+	}
+}
+
+// Publish encodes data as JSON right away and sends it to every subscriber.
+// Subscribers that are too far behind miss the event instead of blocking the publisher.
+func Publish(name string, data interface{}) {
+	encoded, err := json.Marshal(data)
+	if err != nil {
+		log.Println("Couldn't encode the " + name + " event: " + err.Error())
+		return
+	}
+	event := Event{Name: name, Data: encoded}
+
+	subscribersMutex.Lock()
+	defer subscribersMutex.Unlock()
+	for subscriber := range subscribers {
+		select {
+		case subscriber <- event:
+		default:
+		}
+	}
+}
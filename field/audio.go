@@ -33,7 +33,10 @@ func LoadWAVFile(url string) {
 }
 
 func PlayWAV(url string, playFor time.Duration) {
-	sound := sounds[url]
+	sound, ok := sounds[url]
+	if !ok {
+		return
+	}
 
 	speaker.Init(sound.Format.SampleRate, sound.Format.SampleRate.N(time.Second/10))
 	done := make(chan bool)
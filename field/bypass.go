@@ -0,0 +1,46 @@
+package field
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Bypasses (or un-bypasses) an alliance station, a bypassed station doesn't have to be connected for the match to start and it's robot is never enabled
+func (field *Field) SetStationBypassed(station AllianceStation, bypassed bool) error {
+	field.mutex.Lock()
+	defer field.mutex.Unlock()
+	if station < RED1 || station > BLUE3 {
+		return errors.New("that alliance station doesn't exist")
+	}
+	if bypassed {
+		field.BypassedStations[station] = true
+		field.logEvent(fmt.Sprintf("%s (team %d) bypassed", station, field.AllianceStationToTeam[station]))
+	} else {
+		delete(field.BypassedStations, station)
+		field.logEvent(fmt.Sprintf("%s (team %d) no longer bypassed", station, field.AllianceStationToTeam[station]))
+	}
+	field.sendChangedControlPackets()
+	return nil
+}
+
+// Disables (or re-enables) the robot at a single alliance station, this lasts until the next match is setup
+func (field *Field) SetStationDisabled(station AllianceStation, disabled bool) error {
+	field.mutex.Lock()
+	defer field.mutex.Unlock()
+	if station < RED1 || station > BLUE3 {
+		return errors.New("that alliance station doesn't exist")
+	}
+	teamNum := field.AllianceStationToTeam[station]
+	if disabled {
+		field.DisabledStations[station] = true
+		field.logEvent(fmt.Sprintf("%s (team %d) disabled", station, teamNum))
+	} else {
+		delete(field.DisabledStations, station)
+		field.logEvent(fmt.Sprintf("%s (team %d) re-enabled", station, teamNum))
+	}
+	if driverStation := field.getDriverStationByTeamNum(teamNum); driverStation != nil && driverStation.Status != WAITING {
+		driverStation.Disabled = disabled
+	}
+	field.sendChangedControlPackets()
+	return nil
+}
@@ -0,0 +1,75 @@
+package field
+
+import (
+	"math"
+	"time"
+)
+
+// How often the field timer checks the match clock
+const clockResolution = time.Millisecond * 5
+
+// Clock is where the field gets the time from, tests can swap it out to fast forward a match
+type Clock interface {
+	Now() time.Time
+	Sleep(duration time.Duration)
+}
+
+// realClock uses the system's monotonic clock
+type realClock struct{}
+
+func (clock realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (clock realClock) Sleep(duration time.Duration) {
+	time.Sleep(duration)
+}
+
+// Replaces the clock the field uses, this should be done before Run
+func (field *Field) SetClock(clock Clock) {
+	field.mutex.Lock()
+	defer field.mutex.Unlock()
+	field.Clock = clock
+}
+
+// How much of the match has been played, not counting time spent paused
+func (field *Field) matchElapsed(now time.Time) time.Duration {
+	if field.MatchState == PAUSED {
+		now = field.PausedAt
+	}
+	return now.Sub(field.MatchStartedAt) - field.pausedFor
+}
+
+// How much of the match is left to play
+func (field *Field) matchTimeLeft(now time.Time) time.Duration {
+	timeLeft := field.Timing.MatchDuration() - field.matchElapsed(now)
+	if timeLeft < 0 {
+		return 0
+	}
+	return timeLeft
+}
+
+// Updates the time left and phase from the clock, ending the match once time runs out
+func (field *Field) updateClock() {
+	now := field.Clock.Now()
+	timeLeft := field.matchTimeLeft(now)
+	field.TimeLeft = int(math.Ceil(timeLeft.Seconds()))
+	field.TimeLeftMilliseconds = timeLeft.Milliseconds()
+
+	phase := field.Timing.getPhase(field.matchElapsed(now))
+	// A-stops only last for autonomous, however it ends
+	if field.CurrentPhase == AUTONOMOUS && phase != AUTONOMOUS {
+		field.clearAutonomousStops()
+	}
+	if phase == NOTHING {
+		go PlayWAV("audio/ENDMATCH.wav", time.Millisecond*1000)
+		field.CurrentPhase = NOTHING
+		field.stopField(false)
+		return
+	}
+	if field.CurrentPhase != phase {
+		playPhaseCue(phase)
+		field.CurrentPhase = phase
+		field.sendChangedControlPackets()
+	}
+}
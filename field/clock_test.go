@@ -0,0 +1,132 @@
+package field
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/McMackety/nevermore/config"
+)
+
+// fakeClock only moves forward when the test advances it
+type fakeClock struct {
+	mutex sync.Mutex
+	now   time.Time
+}
+
+func (clock *fakeClock) Now() time.Time {
+	clock.mutex.Lock()
+	defer clock.mutex.Unlock()
+	return clock.now
+}
+
+func (clock *fakeClock) Sleep(duration time.Duration) {
+	time.Sleep(duration)
+}
+
+func (clock *fakeClock) Advance(duration time.Duration) {
+	clock.mutex.Lock()
+	defer clock.mutex.Unlock()
+	clock.now = clock.now.Add(duration)
+}
+
+func TestMatchPhases(t *testing.T) {
+	field := newField()
+	clock := &fakeClock{now: time.Date(2020, time.March, 7, 9, 0, 0, 0, time.UTC)}
+	field.SetClock(clock)
+	field.MatchState = READY
+	if err := field.StartField(); err != nil {
+		t.Fatal(err)
+	}
+
+	steps := []struct {
+		advance  time.Duration
+		phase    Phase
+		timeLeft int
+		state    State
+	}{
+		{0, AUTONOMOUS, 151, STARTED},
+		{time.Second * 15, TRANSITION, 136, STARTED},
+		{time.Second, TELEOP, 135, STARTED},
+		{time.Second * 105, ENDGAME, 30, STARTED},
+		{time.Second * 29, ENDGAME, 1, STARTED},
+		{time.Second, NOTHING, 0, INREVIEW},
+	}
+	for _, step := range steps {
+		clock.Advance(step.advance)
+		field.mutex.Lock()
+		if field.MatchState == STARTED {
+			field.updateClock()
+		}
+		if field.CurrentPhase != step.phase || field.TimeLeft != step.timeLeft || field.MatchState != step.state {
+			t.Errorf("%s in: expected phase %d with %d left while %s, got phase %d with %d left while %s", clock.Now().Sub(field.MatchStartedAt),
+				step.phase, step.timeLeft, step.state, field.CurrentPhase, field.TimeLeft, field.MatchState)
+		}
+		field.mutex.Unlock()
+	}
+	if !field.MatchEndedAt.Equal(clock.Now()) {
+		t.Errorf("expected the match to end at %s, it ended at %s", clock.Now(), field.MatchEndedAt)
+	}
+}
+
+func TestPausingStopsTheClock(t *testing.T) {
+	field := newField()
+	clock := &fakeClock{now: time.Date(2020, time.March, 7, 9, 0, 0, 0, time.UTC)}
+	field.SetClock(clock)
+	field.MatchState = READY
+	if err := field.StartField(); err != nil {
+		t.Fatal(err)
+	}
+	clock.Advance(time.Second * 20)
+	if err := field.PauseField("field fault"); err != nil {
+		t.Fatal(err)
+	}
+	clock.Advance(time.Minute * 5)
+	if err := field.ResumeField(); err != nil {
+		t.Fatal(err)
+	}
+	field.mutex.Lock()
+	defer field.mutex.Unlock()
+	field.updateClock()
+	if field.CurrentPhase != TELEOP || field.TimeLeft != 131 {
+		t.Errorf("expected phase %d with 131 left after resuming, got phase %d with %d left", TELEOP, field.CurrentPhase, field.TimeLeft)
+	}
+	if logged := field.Log[len(field.Log)-1]; logged[:12] != "09:05:20.000" {
+		t.Errorf("expected the log to use the field's clock, got %s", logged)
+	}
+}
+
+func TestSetupFieldResetsTheClock(t *testing.T) {
+	field := newField()
+	clock := &fakeClock{now: time.Date(2020, time.March, 7, 9, 0, 0, 0, time.UTC)}
+	field.SetClock(clock)
+	field.MatchState = READY
+	if err := field.StartField(); err != nil {
+		t.Fatal(err)
+	}
+	clock.Advance(time.Second * 20)
+	field.mutex.Lock()
+	field.updateClock()
+	field.mutex.Unlock()
+	if err := field.StopField(true); err != nil {
+		t.Fatal(err)
+	}
+
+	autoLength, teleopLength := 10, 60
+	oldProfiles := config.DefaultConfig.TimingProfiles
+	defer func() { config.DefaultConfig.TimingProfiles = oldProfiles }()
+	config.DefaultConfig.TimingProfiles = map[string]config.TimingProfileConfig{
+		QUALIFICATION.String(): {AutoLength: &autoLength, TeleopLength: &teleopLength},
+	}
+	if err := field.SetupField(1, QUALIFICATION, 0, 0, 0, 0, 0, 0); err != nil {
+		t.Fatal(err)
+	}
+	field.mutex.Lock()
+	defer field.mutex.Unlock()
+	if expected := field.Timing.MatchLength(); field.TimeLeft != expected || field.TimeLeftMilliseconds != int64(expected)*1000 {
+		t.Errorf("expected %d left in the new match, got %d (%dms)", expected, field.TimeLeft, field.TimeLeftMilliseconds)
+	}
+	if field.CurrentPhase != NOTHING {
+		t.Errorf("expected the new match to be in phase %d, got %d", NOTHING, field.CurrentPhase)
+	}
+}
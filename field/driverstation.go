@@ -1,6 +1,9 @@
 package field
 
 import (
+	"fmt"
+	"github.com/McMackety/nevermore/events"
+	"log"
 	"net"
 	"time"
 )
@@ -11,6 +14,9 @@ type DriverStation struct {
 	TeamNumber       int             `json:"teamNum"`
 	EmergencyStopped bool            `json:"eStop"`
 	RequestEmergencyStop bool        `json:"requestEStop"`
+	AutonomousStopped bool           `json:"aStop"`
+	RequestAutonomousStop bool       `json:"requestAStop"`
+	Disabled         bool            `json:"disabled"`
 	Comms            bool            `json:"comms"`
 	RadioPing        bool            `json:"radioPing"`
 	RioPing          bool            `json:"rioPing"`
@@ -22,16 +28,34 @@ type DriverStation struct {
 	Status           Status          `json:"status"`
 	LastUDPMessage   time.Time       `json:"-"`
 	UDPConn          net.Conn        `json:"-"`
+	Versions         map[string]string `json:"versions"`
+	TripTime         float64         `json:"tripTime"`
+	LostPackets      int             `json:"lostPackets"`
+	CPUUtilization   float64         `json:"cpuUtilization"`
+	CANUtilization   float64         `json:"canUtilization"`
+	SignalDB         int             `json:"signalDB"`
+	Bandwidth        float64         `json:"bandwidth"`
+	Brownout         bool            `json:"brownout"`
+	Watchdog         bool            `json:"watchdog"`
+	lastControlState controlState
 }
 
 // Creates a new driver station connection.
-func (field *Field) createDriverStation(teamNum int, socket net.Conn, udpSocket net.Conn) {
+func (field *Field) createDriverStation(teamNum int, socket net.Conn, udpSocket net.Conn) *DriverStation {
+	// A team can only have one connection, drop the old one if it reconnects.
+	if oldDriverStation := field.getDriverStationByTeamNum(teamNum); oldDriverStation != nil {
+		oldDriverStation.kickWithReason("reconnected")
+	}
+
 	driverStation := &DriverStation{
 		TCPSocket:        socket,
 		CurrentField:     field,
 		TeamNumber:       teamNum,
 		EmergencyStopped: false,
 		RequestEmergencyStop: false,
+		AutonomousStopped: false,
+		RequestAutonomousStop: false,
+		Disabled:         false,
 		Comms:            false,
 		RadioPing:        false,
 		RioPing:          false,
@@ -40,43 +64,65 @@ func (field *Field) createDriverStation(teamNum int, socket net.Conn, udpSocket
 		UDPSequenceNum:   0,
 		LastUDPMessage:   time.Now(),
 		UDPConn:          udpSocket,
+		Versions:         make(map[string]string),
 	}
 
 	field.TeamNumberToDriverStation[teamNum] = driverStation
+	field.getHistory(teamNum).addConnection(true, "connected from "+socket.RemoteAddr().String())
 
-	if !field.IsTeamInMatch(teamNum) {
+	if station, ok := field.getAllianceStationFromTeamNum(teamNum); !ok {
 		driverStation.Status = WAITING
 	} else {
-		driverStation.Status = GOOD
-		driverStation.Station = field.GetAllianceStationFromTeamNum(teamNum)
+		driverStation.Station = station
+		driverStation.checkStation()
+		driverStation.loadStationStops()
 	}
 
 	// Send Event and Station Info
 	driverStation.SendStationInfo()
 	driverStation.SendEventName()
+	if data := field.GameSpecificData[driverStation.Station.Alliance()]; data != "" && driverStation.Status == GOOD {
+		driverStation.SendGameSpecificData(data)
+	}
+
+	return driverStation
+}
+
+// Takes the e-stop, a-stop and disable from the driverstation's station.
+// They belong to the station for the whole match, even if the driverstation reconnects or is moved.
+func (driverStation *DriverStation) loadStationStops() {
+	field := driverStation.CurrentField
+	driverStation.EmergencyStopped = field.EmergencyStoppedStations[driverStation.Station]
+	driverStation.AutonomousStopped = field.AutonomousStoppedStations[driverStation.Station]
+	driverStation.Disabled = field.DisabledStations[driverStation.Station]
+}
+
+// Puts the driverstation in a station, checking it's plugged into the right one and telling it where it is
+func (driverStation *DriverStation) placeInStation(station AllianceStation) {
+	driverStation.Station = station
+	driverStation.checkStation()
+	driverStation.loadStationStops()
+	driverStation.SendStationInfo()
+	driverStation.sendControlPacketIfChanged()
 }
 
 // Returns true if in autonomous period, false if not.
 func (driverStation *DriverStation) IsInAutonomous() bool {
-	if driverStation.CurrentField.TimeLeft > TransitionLength+TeleopLength+EndgameLength {
-		return true
+	field := driverStation.CurrentField
+	if field.MatchState != STARTED && field.MatchState != PAUSED {
+		return false
 	}
-	return false
+	return field.Timing.getPhase(field.matchElapsed(field.Clock.Now())) == AUTONOMOUS
 }
 
-// Returns true if in autonomous period, false if not.
+// Returns true if the match is in a period where robots should be enabled, checked against the clock right now.
 func (driverStation *DriverStation) ShouldBeEnabled() bool {
-	if driverStation.CurrentField.MatchState != STARTED {
-		return false
-	}
-	if driverStation.CurrentField.TimeLeft > TransitionLength+TeleopLength+EndgameLength {
-		return true
-	} else if driverStation.CurrentField.TimeLeft > TeleopLength+EndgameLength {
+	field := driverStation.CurrentField
+	if field.MatchState != STARTED {
 		return false
-	} else if driverStation.CurrentField.TimeLeft > 0 {
-		return true
 	}
-	return false
+	phase := field.Timing.getPhase(field.matchElapsed(field.Clock.Now()))
+	return phase == AUTONOMOUS || phase == TELEOP || phase == ENDGAME
 }
 
 // Sends the name of the event
@@ -101,8 +147,26 @@ func (driverStation *DriverStation) SendStationInfo() {
 	driverStation.TCPSocket.Write(prefixWithSize(data))
 }
 
+// Sends the game specific data (the control panel color for Infinite Recharge)
+func (driverStation *DriverStation) SendGameSpecificData(data string) {
+	packet := []byte{
+		0x1c,
+	}
+
+	packet = append(packet, []byte(data)...)
+	driverStation.TCPSocket.Write(prefixWithSize(packet))
+}
+
 // Kicks the driverstation
 func (driverStation *DriverStation) Kick() {
+	driverStation.CurrentField.mutex.Lock()
+	defer driverStation.CurrentField.mutex.Unlock()
+	driverStation.kickWithReason("kicked by the FMS")
+}
+
+// Kicks the driverstation, recording why in the team's history
+func (driverStation *DriverStation) kickWithReason(reason string) {
+	driverStation.CurrentField.getHistory(driverStation.TeamNumber).addConnection(false, reason)
 	delete(driverStation.CurrentField.TeamNumberToDriverStation, driverStation.TeamNumber)
 	driverStation.TCPSocket.Close()
 	driverStation.UDPConn.Close()
@@ -111,72 +175,127 @@ func (driverStation *DriverStation) Kick() {
 // Ticks the driverstation, ran every 500 ms
 func (driverStation *DriverStation) tick() {
 	if time.Since(driverStation.LastUDPMessage).Seconds() > 2 {
-		driverStation.Kick()
+		driverStation.kickWithReason("no UDP packets for 2 seconds")
 	} else {
 		// Update all Web Clients for updates every tick.
 		// Uses "driverStationTick_{teamNum} as Event Name
-		enabled := driverStation.Enabled
-		if !driverStation.ShouldBeEnabled() {
-			if driverStation.CurrentField.MatchLevel != MATCHTEST {
-				enabled = false
-			}
+		events.Publish(fmt.Sprintf("driverStationTick_%d", driverStation.TeamNumber), driverStation)
+	}
+}
+
+// controlState is what the FMS is telling the robot to do, a control packet is sent right away whenever it changes
+type controlState struct {
+	Enabled           bool
+	Autonomous        bool
+	EmergencyStopped  bool
+	AutonomousStopped bool
+}
+
+// Works out what the robot should be doing right now
+func (driverStation *DriverStation) getControlState() controlState {
+	enabled := driverStation.Enabled
+	if !driverStation.ShouldBeEnabled() {
+		if driverStation.CurrentField.MatchLevel != MATCHTEST {
+			enabled = false
 		}
+	}
+	if driverStation.EmergencyStopped {
+		enabled = false
+	}
+	// An a-stop only lasts for autonomous, the field clears it when autonomous ends
+	if driverStation.AutonomousStopped {
+		enabled = false
+	}
+	if driverStation.Disabled || driverStation.isBypassed() {
+		enabled = false
+	}
+	if driverStation.Status == BAD {
+		enabled = false
+	}
+	// A team that isn't in the match can only be driven in match test
+	if driverStation.Status == WAITING && driverStation.CurrentField.MatchLevel != MATCHTEST {
+		enabled = false
+	}
+	return controlState{
+		Enabled:           enabled,
+		Autonomous:        driverStation.IsInAutonomous(),
+		EmergencyStopped:  driverStation.EmergencyStopped,
+		AutonomousStopped: driverStation.AutonomousStopped,
+	}
+}
 
-		autonomous := driverStation.IsInAutonomous()
+// Returns true if the driverstation's station is bypassed
+func (driverStation *DriverStation) isBypassed() bool {
+	return driverStation.Status != WAITING && driverStation.CurrentField.BypassedStations[driverStation.Station]
+}
 
-		var packet [22]byte
-		packet[0] = byte(driverStation.UDPSequenceNum >> 8 & 0xff)
-		packet[1] = byte(driverStation.UDPSequenceNum & 0xff)
+// Sends a control packet only if the robot should be doing something different from the last packet
+func (driverStation *DriverStation) sendControlPacketIfChanged() {
+	if driverStation.getControlState() != driverStation.lastControlState {
+		driverStation.sendControlPacket()
+	}
+}
 
-		packet[2] = 0
+// Sends the UDP control packet to the driverstation
+func (driverStation *DriverStation) sendControlPacket() {
+	state := driverStation.getControlState()
 
-		packet[3] = 0
-		if autonomous {
-			packet[3] |= 0x02
-		}
-		if enabled {
-			packet[3] |= 0x04
-		}
-		if driverStation.EmergencyStopped {
-			packet[3] |= 0x80
-		}
+	var packet [22]byte
+	packet[0] = byte(driverStation.UDPSequenceNum >> 8 & 0xff)
+	packet[1] = byte(driverStation.UDPSequenceNum & 0xff)
+
+	packet[2] = 0
 
-		packet[4] = 0 // Unknown
+	packet[3] = 0
+	if state.Autonomous {
+		packet[3] |= 0x02
+	}
+	if state.Enabled {
+		packet[3] |= 0x04
+	}
+	if state.AutonomousStopped {
+		packet[3] |= 0x40
+	}
+	if state.EmergencyStopped {
+		packet[3] |= 0x80
+	}
 
-		packet[5] = byte(driverStation.Station)
+	packet[4] = 0 // Unknown
 
-		packet[6] = byte(driverStation.CurrentField.MatchLevel)
+	packet[5] = byte(driverStation.Station)
 
-		packet[7] = byte(driverStation.CurrentField.MatchNumber >> 8 & 0xff)
+	packet[6] = byte(driverStation.CurrentField.MatchLevel)
 
-		packet[8] = byte(driverStation.CurrentField.MatchNumber & 0xff)
+	packet[7] = byte(driverStation.CurrentField.MatchNumber >> 8 & 0xff)
 
-		packet[9] = 1 // Useless Replay Number (To Us)
+	packet[8] = byte(driverStation.CurrentField.MatchNumber & 0xff)
 
-		// Current time.
-		currentTime := time.Now()
-		packet[10] = byte(((currentTime.Nanosecond() / 1000) >> 24) & 0xff)
-		packet[11] = byte(((currentTime.Nanosecond() / 1000) >> 16) & 0xff)
-		packet[12] = byte(((currentTime.Nanosecond() / 1000) >> 8) & 0xff)
-		packet[13] = byte((currentTime.Nanosecond() / 1000) & 0xff)
-		packet[14] = byte(currentTime.Second())
-		packet[15] = byte(currentTime.Minute())
-		packet[16] = byte(currentTime.Hour())
-		packet[17] = byte(currentTime.Day())
-		packet[18] = byte(currentTime.Month())
-		packet[19] = byte(currentTime.Year() - 1900)
+	packet[9] = 1 // Useless Replay Number (To Us)
 
-		packet[20] = byte(GetFormattedTime(driverStation.CurrentField.TimeLeft) >> 8 & 0xff)
-		packet[21] = byte(GetFormattedTime(driverStation.CurrentField.TimeLeft) & 0xff)
+	// Current time.
+	currentTime := time.Now()
+	packet[10] = byte(((currentTime.Nanosecond() / 1000) >> 24) & 0xff)
+	packet[11] = byte(((currentTime.Nanosecond() / 1000) >> 16) & 0xff)
+	packet[12] = byte(((currentTime.Nanosecond() / 1000) >> 8) & 0xff)
+	packet[13] = byte((currentTime.Nanosecond() / 1000) & 0xff)
+	packet[14] = byte(currentTime.Second())
+	packet[15] = byte(currentTime.Minute())
+	packet[16] = byte(currentTime.Hour())
+	packet[17] = byte(currentTime.Day())
+	packet[18] = byte(currentTime.Month())
+	packet[19] = byte(currentTime.Year() - 1900)
 
-		driverStation.UDPConn.Write(packet[:])
+	packet[20] = byte(driverStation.CurrentField.Timing.GetFormattedTime(driverStation.CurrentField.TimeLeft) >> 8 & 0xff)
+	packet[21] = byte(driverStation.CurrentField.Timing.GetFormattedTime(driverStation.CurrentField.TimeLeft) & 0xff)
 
-		driverStation.UDPSequenceNum++
-	}
+	driverStation.UDPConn.Write(packet[:])
+
+	driverStation.UDPSequenceNum++
+	driverStation.lastControlState = state
 }
 
 // Called whenever a UDP message was received
-func (driverStation *DriverStation) receiveUDP(eStop bool, comms bool, radioPing bool, rioPing bool, enabled bool, mode Mode, batteryVoltage float64) {
+func (driverStation *DriverStation) receiveUDP(eStop bool, aStop bool, comms bool, radioPing bool, rioPing bool, enabled bool, mode Mode, batteryVoltage float64) {
 	driverStation.LastUDPMessage = time.Now()
 	driverStation.Comms = comms
 	driverStation.RadioPing = radioPing
@@ -184,6 +303,63 @@ func (driverStation *DriverStation) receiveUDP(eStop bool, comms bool, radioPing
 	driverStation.BatteryVoltage = batteryVoltage
 	driverStation.RequestEnabled = enabled
 	driverStation.RequestEmergencyStop = eStop
+	driverStation.RequestAutonomousStop = aStop
+	if eStop && !driverStation.EmergencyStopped {
+		if driverStation.Status != WAITING {
+			driverStation.CurrentField.emergencyStopStation(driverStation.Station, "pressed on the driverstation")
+		} else {
+			// The team isn't in the match, so there's no station to latch
+			driverStation.EmergencyStopped = true
+			driverStation.sendControlPacket()
+		}
+	}
+	// The a-stop button does nothing outside of auto
+	if aStop && !driverStation.AutonomousStopped && driverStation.Status != WAITING && driverStation.CurrentField.canAutonomousStop() {
+		driverStation.CurrentField.autonomousStopStation(driverStation.Station, "pressed on the driverstation")
+	}
+}
+
+// Called whenever a TCP message was received
+func (driverStation *DriverStation) receiveTCP(message TCPMessage) {
+	switch message := message.(type) {
+	case TeamNumberMessage:
+		if message.TeamNumber != driverStation.TeamNumber {
+			log.Printf("Driverstation for team %d reported a different team number (%d), ignoring it", driverStation.TeamNumber, message.TeamNumber)
+		}
+	case KeepAliveMessage:
+	case VersionMessage:
+		driverStation.Versions[message.Name] = message.Version
+	case LogDataMessage:
+		driverStation.TripTime = message.TripTime
+		driverStation.LostPackets = message.LostPackets
+		driverStation.CPUUtilization = message.CPUUtilization
+		driverStation.CANUtilization = message.CANUtilization
+		driverStation.SignalDB = message.SignalDB
+		driverStation.Bandwidth = message.Bandwidth
+		driverStation.Brownout = message.Brownout
+		driverStation.Watchdog = message.Watchdog
+		driverStation.CurrentField.getHistory(driverStation.TeamNumber).addTelemetry(TelemetryEntry{
+			Time:           time.Now(),
+			TripTime:       message.TripTime,
+			LostPackets:    message.LostPackets,
+			BatteryVoltage: message.BatteryVoltage,
+			Brownout:       message.Brownout,
+			Watchdog:       message.Watchdog,
+			CPUUtilization: message.CPUUtilization,
+			CANUtilization: message.CANUtilization,
+			SignalDB:       message.SignalDB,
+			Bandwidth:      message.Bandwidth,
+		})
+	case ErrorMessage:
+		driverStation.CurrentField.getHistory(driverStation.TeamNumber).addMessage(MessageEntry{
+			Time:    message.Timestamp,
+			Code:    message.Code,
+			IsError: message.IsError,
+			Details: message.Details,
+		})
+	case UnknownMessage:
+		log.Printf("Driverstation for team %d sent an unknown TCP tag 0x%02x", driverStation.TeamNumber, byte(message.MessageTag))
+	}
 }
 
 func prefixWithSize(bytes []byte) []byte {
@@ -1,5 +1,9 @@
 package field
 
+import (
+	"github.com/McMackety/nevermore/enums"
+)
+
 // Alliance is the Red/Blue alliance.
 type Alliance int
 
@@ -22,6 +26,32 @@ const (
 	BLUE3
 )
 
+func (station AllianceStation) String() string {
+	switch station {
+	case RED1:
+		return "RED1"
+	case RED2:
+		return "RED2"
+	case RED3:
+		return "RED3"
+	case BLUE1:
+		return "BLUE1"
+	case BLUE2:
+		return "BLUE2"
+	case BLUE3:
+		return "BLUE3"
+	}
+	return "UNKNOWN"
+}
+
+// Alliance returns the alliance the station belongs to
+func (station AllianceStation) Alliance() Alliance {
+	if station >= BLUE1 {
+		return BLUE
+	}
+	return RED
+}
+
 // Status is the status of the robot
 type Status int
 
@@ -42,17 +72,22 @@ const (
 	AUTONOMOUSMODE
 )
 
-// Level is the tournament level.
-type Level int
+// Level is the tournament level, it's shared with the packages that can't import field.
+type Level = enums.Level
 
 // The different tournament levels.
 const (
-	MATCHTEST Level = iota
-	PRACTICE
-	QUALIFICATION
-	PLAYOFF
+	MATCHTEST     = enums.MATCHTEST
+	PRACTICE      = enums.PRACTICE
+	QUALIFICATION = enums.QUALIFICATION
+	PLAYOFF       = enums.PLAYOFF
 )
 
+// Parses a tournament level from it's name, like "QUALIFICATION"
+func ParseLevel(name string) (Level, error) {
+	return enums.ParseLevel(name)
+}
+
 // State is the currentState of the field.
 type State int
 
@@ -66,6 +101,24 @@ const (
 	DONE
 )
 
+func (state State) String() string {
+	switch state {
+	case NOTREADY:
+		return "NOTREADY"
+	case READY:
+		return "READY"
+	case STARTED:
+		return "STARTED"
+	case PAUSED:
+		return "PAUSED"
+	case INREVIEW:
+		return "INREVIEW"
+	case DONE:
+		return "DONE"
+	}
+	return "UNKNOWN"
+}
+
 // Phase is the different phases the field can be in.
 type Phase int
 
@@ -76,4 +129,14 @@ const (
 	TRANSITION
 	TELEOP
 	ENDGAME
-)
\ No newline at end of file
+)
+
+// Card is a card given to a team by the referees, it's shared with the packages that can't import field.
+type Card = enums.Card
+
+// The different cards
+const (
+	NOCARD     = enums.NOCARD
+	YELLOWCARD = enums.YELLOWCARD
+	REDCARD    = enums.REDCARD
+)
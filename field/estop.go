@@ -0,0 +1,94 @@
+package field
+
+import (
+	"errors"
+	"fmt"
+	"github.com/McMackety/nevermore/events"
+)
+
+// EmergencyStop is published as the emergencyStop event whenever a station is e-stopped
+type EmergencyStop struct {
+	Station    AllianceStation `json:"allianceStation"`
+	TeamNumber int             `json:"teamNum"`
+	Reason     string          `json:"reason"`
+}
+
+// AutonomousStop is published as the autonomousStop event whenever a station is a-stopped
+type AutonomousStop struct {
+	Station    AllianceStation `json:"allianceStation"`
+	TeamNumber int             `json:"teamNum"`
+	Reason     string          `json:"reason"`
+}
+
+// E-stops an alliance station from the scoring table, it stays e-stopped until the next match is setup
+func (field *Field) EmergencyStopStation(station AllianceStation) error {
+	field.mutex.Lock()
+	defer field.mutex.Unlock()
+	if station < RED1 || station > BLUE3 {
+		return errors.New("that alliance station doesn't exist")
+	}
+	field.emergencyStopStation(station, "pressed at the scoring table")
+	return nil
+}
+
+// Latches the e-stop for a station and disables it's robot right away
+func (field *Field) emergencyStopStation(station AllianceStation, reason string) {
+	if field.EmergencyStoppedStations[station] {
+		return
+	}
+	field.EmergencyStoppedStations[station] = true
+	teamNum := field.AllianceStationToTeam[station]
+	if driverStation := field.getDriverStationByTeamNum(teamNum); driverStation != nil && driverStation.Status != WAITING {
+		driverStation.EmergencyStopped = true
+		driverStation.sendControlPacket()
+	}
+	field.logEvent(fmt.Sprintf("%s (team %d) e-stopped: %s", station, teamNum, reason))
+	events.Publish("emergencyStop", EmergencyStop{Station: station, TeamNumber: teamNum, Reason: reason})
+}
+
+// A-stops an alliance station from the scoring table, the robot is disabled for the rest of auto
+func (field *Field) AutonomousStopStation(station AllianceStation) error {
+	field.mutex.Lock()
+	defer field.mutex.Unlock()
+	if station < RED1 || station > BLUE3 {
+		return errors.New("that alliance station doesn't exist")
+	}
+	if !field.canAutonomousStop() {
+		return errors.New("a robot can only be a-stopped during autonomous")
+	}
+	field.autonomousStopStation(station, "pressed at the scoring table")
+	return nil
+}
+
+// A-stops are only taken during autonomous, the same rule is used for the scoring table and the driverstation.
+// A match paused in autonomous is still in autonomous, so robots can be a-stopped before it resumes.
+func (field *Field) canAutonomousStop() bool {
+	return (field.MatchState == STARTED || field.MatchState == PAUSED) && field.CurrentPhase == AUTONOMOUS
+}
+
+// Disables a station's robot until autonomous ends
+func (field *Field) autonomousStopStation(station AllianceStation, reason string) {
+	if field.AutonomousStoppedStations[station] {
+		return
+	}
+	field.AutonomousStoppedStations[station] = true
+	teamNum := field.AllianceStationToTeam[station]
+	if driverStation := field.getDriverStationByTeamNum(teamNum); driverStation != nil && driverStation.Status != WAITING {
+		driverStation.AutonomousStopped = true
+		driverStation.sendControlPacket()
+	}
+	field.logEvent(fmt.Sprintf("%s (team %d) a-stopped: %s", station, teamNum, reason))
+	events.Publish("autonomousStop", AutonomousStop{Station: station, TeamNumber: teamNum, Reason: reason})
+}
+
+// Lets every a-stopped robot back in, this is called by the field timer when autonomous ends
+func (field *Field) clearAutonomousStops() {
+	if len(field.AutonomousStoppedStations) == 0 {
+		return
+	}
+	field.AutonomousStoppedStations = make(map[AllianceStation]bool)
+	for _, driverStation := range field.TeamNumberToDriverStation {
+		driverStation.AutonomousStopped = false
+	}
+	field.logEvent("a-stops cleared at the end of autonomous")
+}
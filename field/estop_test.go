@@ -0,0 +1,57 @@
+package field
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAutonomousStopOnlyDuringAutonomous(t *testing.T) {
+	tests := []struct {
+		state   State
+		phase   Phase
+		allowed bool
+	}{
+		{READY, NOTHING, false},
+		{STARTED, AUTONOMOUS, true},
+		{PAUSED, AUTONOMOUS, true},
+		{STARTED, TRANSITION, false},
+		{STARTED, TELEOP, false},
+		{PAUSED, TELEOP, false},
+		{STARTED, ENDGAME, false},
+		{INREVIEW, AUTONOMOUS, false},
+	}
+	for _, test := range tests {
+		field := newField()
+		field.MatchState = test.state
+		field.CurrentPhase = test.phase
+		err := field.AutonomousStopStation(RED2)
+		if test.allowed && err != nil {
+			t.Errorf("expected an a-stop to be allowed when %s in phase %d: %s", test.state, test.phase, err)
+		} else if !test.allowed && err == nil {
+			t.Errorf("expected an a-stop to be rejected when %s in phase %d", test.state, test.phase)
+		}
+		if field.canAutonomousStop() != test.allowed {
+			t.Errorf("expected the driverstation's a-stop rule to match the scoring table's when %s in phase %d", test.state, test.phase)
+		}
+	}
+}
+
+func TestAutonomousStopsClearAfterAutonomous(t *testing.T) {
+	field := newField()
+	clock := &fakeClock{now: time.Date(2020, time.March, 7, 9, 0, 0, 0, time.UTC)}
+	field.SetClock(clock)
+	field.MatchState = READY
+	if err := field.StartField(); err != nil {
+		t.Fatal(err)
+	}
+	if err := field.AutonomousStopStation(BLUE1); err != nil {
+		t.Fatal(err)
+	}
+	clock.Advance(time.Second * 15)
+	field.mutex.Lock()
+	defer field.mutex.Unlock()
+	field.updateClock()
+	if field.CurrentPhase != TRANSITION || len(field.AutonomousStoppedStations) != 0 {
+		t.Errorf("expected the a-stop to be cleared once autonomous ended, got %v in phase %d", field.AutonomousStoppedStations, field.CurrentPhase)
+	}
+}
@@ -1,63 +1,104 @@
 package field
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"github.com/McMackety/nevermore/config"
+	"github.com/McMackety/nevermore/events"
 	"github.com/McMackety/nevermore/scoring"
 	"log"
 	"net"
-	"strings"
+	"strconv"
+	"sync"
 	"time"
 )
 
 // The current field, should be obvious
 var CurrentField *Field
 
-// Field is the structure the represents a FRC field
+// Field is the structure the represents a FRC field.
+// Everything on it is guarded by mutex, exported methods lock it and unexported ones expect it to already be locked.
 type Field struct {
+	mutex                     sync.Mutex
 	MatchNumber               int `json:"matchNum"`
 	MatchState				  State `json:"matchState"`
 	TimeLeft                  int `json:"timeLeft"`
+	TimeLeftMilliseconds      int64 `json:"timeLeftMs"`
 	EventName                 string `json:"eventName"`
 	CurrentPhase              Phase `json:"currentPhase"`
 	MatchLevel                Level `json:"matchLevel"`
 	MatchStartedAt            time.Time `json:"matchStartedAt"`
-	Scorer 					  scoring.ScoringInterface
+	MatchEndedAt              time.Time `json:"matchEndedAt"`
+	Scorer 					  scoring.ScoringInterface `json:"-"`
 	TeamNumberToDriverStation map[int]*DriverStation `json:"teamNumberToDriverStation"`
 	AllianceStationToTeam     map[AllianceStation]int `json:"allianceStationToTeam"`
 	UDPSocket                 *net.UDPConn `json:"-"`
 	Log 					  []string `json:"-"`
+	DriverStationHistories    map[int]*DriverStationHistory `json:"-"`
+	GameSpecificData          map[Alliance]string `json:"-"`
+	Cards                     map[AllianceStation]Card `json:"-"`
+	EmergencyStoppedStations  map[AllianceStation]bool `json:"emergencyStoppedStations"`
+	AutonomousStoppedStations map[AllianceStation]bool `json:"autonomousStoppedStations"`
+	BypassedStations          map[AllianceStation]bool `json:"bypassedStations"`
+	DisabledStations          map[AllianceStation]bool `json:"disabledStations"`
+	PausedAt                  time.Time `json:"pausedAt"`
+	PauseReason               string `json:"pauseReason"`
+	Timing                    TimingProfile `json:"timing"`
+	Clock                     Clock `json:"-"`
+	pausedFor                 time.Duration
+	committing                bool
+	stationSubnets            map[AllianceStation]*net.IPNet
 }
 
 // CreateField creates a field
 func CreateField() {
 	log.Println("Initializing Field...")
-	// Check for a network interface with 10.0.100.5
-	correctNetwork := false
-	addrs, _ := net.InterfaceAddrs()
-	log.Println("Scanning Network Interfaces...")
-	for _, addr := range addrs {
-		if strings.HasPrefix(addr.String(), "10.0.100.5") {
-			correctNetwork = true
+	network := config.DefaultConfig.Network
+	if network.DevelopmentMode {
+		log.Println("Running in development mode, skipping the network interface check.")
+	} else {
+		// Check for a network interface with the FMS's address
+		correctNetwork := false
+		addrs, _ := net.InterfaceAddrs()
+		log.Println("Scanning Network Interfaces...")
+		for _, addr := range addrs {
+			if ip, _, err := net.ParseCIDR(addr.String()); err == nil && ip.String() == network.BindAddress {
+				correctNetwork = true
+			}
 		}
+		if !correctNetwork {
+			log.Panicln("Couldn't find network interface with an IP of " + network.BindAddress + ", the FMS can't startup!")
+		}
+		log.Println("Found network interface with " + network.BindAddress + "!")
 	}
-	if !correctNetwork {
-		log.Panicln("Couldn't find network interface with an IP of 10.0.100.5, the FMS can't startup!")
-	}
-	log.Println("Found network interface with 10.0.100.5!")
 
-	field := Field{
+	CurrentField = newField()
+}
+
+// Makes an empty field that isn't connected to anything yet
+func newField() *Field {
+	return &Field{
 		TeamNumberToDriverStation: make(map[int]*DriverStation),
 		AllianceStationToTeam:     make(map[AllianceStation]int),
+		DriverStationHistories:    make(map[int]*DriverStationHistory),
+		GameSpecificData:          make(map[Alliance]string),
+		Cards:                     make(map[AllianceStation]Card),
+		EmergencyStoppedStations:  make(map[AllianceStation]bool),
+		AutonomousStoppedStations: make(map[AllianceStation]bool),
+		BypassedStations:          make(map[AllianceStation]bool),
+		DisabledStations:          make(map[AllianceStation]bool),
 		MatchState:				   NOTREADY,
 		Scorer: 				   scoring.CreateScoringInterface(),
 		MatchStartedAt:            time.Now(),
 		MatchLevel:                PRACTICE,
+		Timing:                    GetTimingProfile(PRACTICE),
+		Clock:                     realClock{},
 		MatchNumber:               0,
 		EventName:                 "EAO",
 		CurrentPhase: 			   NOTHING,
+		stationSubnets:            loadStationSubnets(),
 	}
-	CurrentField = &field
 }
 
 // Starts the FMS's networking
@@ -68,54 +109,256 @@ func (field *Field) Run() {
 	LoadWAVFile("audio/warning.wav")
 	go field.fieldTimer()
 	go field.tick()
+	go field.sendControlPackets()
 	go field.listenTCP()
 	go field.listenUDP()
 }
 
-// Sets up the field from scratch
-func (field *Field) SetupField(matchNum int, tournamentLevel Level, red1 int, red2 int, red3 int, blue1 int, blue2 int, blue3 int) {
-	field.KickAllDriverStations()
+// Sets up the field from scratch, this can't be done while a match is running or in review
+func (field *Field) SetupField(matchNum int, tournamentLevel Level, red1 int, red2 int, red3 int, blue1 int, blue2 int, blue3 int) error {
+	field.mutex.Lock()
+	defer field.mutex.Unlock()
+	if field.MatchState != NOTREADY {
+		if err := field.transitionTo(NOTREADY); err != nil {
+			return err
+		}
+	}
+	field.kickAllDriverStations()
 	field.MatchNumber = matchNum
-	field.MatchState = NOTREADY
 	field.MatchLevel = tournamentLevel
+	field.Timing = GetTimingProfile(tournamentLevel)
+	// The clock isn't updated until the match starts, so a match that was stopped early would still show it's time
+	field.TimeLeft = field.Timing.MatchLength()
+	field.TimeLeftMilliseconds = field.Timing.MatchDuration().Milliseconds()
+	field.CurrentPhase = NOTHING
 	field.AllianceStationToTeam[RED1] = red1
 	field.AllianceStationToTeam[RED2] = red2
 	field.AllianceStationToTeam[RED3] = red3
 	field.AllianceStationToTeam[BLUE1] = blue1
 	field.AllianceStationToTeam[BLUE2] = blue2
 	field.AllianceStationToTeam[BLUE3] = blue3
-	field.Log = make([]string, 50)
+	field.Log = make([]string, 0, 50)
+	field.DriverStationHistories = make(map[int]*DriverStationHistory)
+	field.GameSpecificData = make(map[Alliance]string)
+	field.Scorer = scoring.CreateScoringInterface()
+	field.Cards = make(map[AllianceStation]Card)
+	field.EmergencyStoppedStations = make(map[AllianceStation]bool)
+	field.AutonomousStoppedStations = make(map[AllianceStation]bool)
+	field.BypassedStations = make(map[AllianceStation]bool)
+	field.DisabledStations = make(map[AllianceStation]bool)
+	field.logEvent(fmt.Sprintf("Setup match %d", matchNum))
+	return nil
 }
 
 // Starts the field
 func (field *Field) StartField() error {
-	if !field.AllTeamsOnField() {
+	field.mutex.Lock()
+	defer field.mutex.Unlock()
+	if !field.allTeamsOnField() {
 		return errors.New("the match is not ready, not all driverStations have connected")
 	}
 	if field.MatchState == STARTED {
 		return errors.New("the match has already started, setup the match before you restart it")
 	}
-	field.TimeLeft = TransitionLength + TeleopLength + EndgameLength + AutoLength
-	field.MatchState = STARTED
+	// Only ResumeField can leave PAUSED, starting again would reset the match clock
+	if field.MatchState == PAUSED {
+		return errors.New("the match is paused, resume it instead")
+	}
+	if field.MatchState != READY {
+		return errors.New("the match is not ready")
+	}
+	if err := field.transitionTo(STARTED); err != nil {
+		return err
+	}
+	field.MatchStartedAt = field.Clock.Now()
+	field.pausedFor = 0
+	field.CurrentPhase = NOTHING
+	field.updateClock()
 	return nil
 }
 
-// Stops the field
+// Stops the field, a normal stop starts the review and an early stop aborts the match without saving a result
 func (field *Field) StopField(isEarly bool) error {
-	if field.MatchState != STARTED {
+	field.mutex.Lock()
+	defer field.mutex.Unlock()
+	return field.stopField(isEarly)
+}
+
+func (field *Field) stopField(isEarly bool) error {
+	if field.MatchState != STARTED && field.MatchState != PAUSED {
 		return errors.New("no matches have started")
 	}
+	field.disableAllRobots()
+	field.MatchEndedAt = field.Clock.Now()
 	if isEarly {
-		field.MatchState = DONE
+		return field.transitionTo(DONE)
+	}
+	return field.transitionTo(INREVIEW)
+}
+
+// Assigns a team to an alliance station
+func (field *Field) AddTeam(station AllianceStation, teamNum int) error {
+	field.mutex.Lock()
+	defer field.mutex.Unlock()
+	if station < RED1 || station > BLUE3 {
+		return errors.New("that alliance station doesn't exist")
+	}
+	if oldTeamNum, ok := field.AllianceStationToTeam[station]; ok && oldTeamNum != teamNum {
+		field.removeFromStation(station, oldTeamNum)
+	}
+	field.AllianceStationToTeam[station] = teamNum
+	// A driverstation that connected before it's team was added has been waiting for a station
+	if driverStation := field.getDriverStationByTeamNum(teamNum); driverStation != nil {
+		driverStation.placeInStation(station)
+	}
+	return nil
+}
+
+// Removes the team from an alliance station, it's driverstation stays connected but goes back to waiting for a station
+func (field *Field) RemoveTeamByStation(station AllianceStation) error {
+	field.mutex.Lock()
+	defer field.mutex.Unlock()
+	teamNum, ok := field.AllianceStationToTeam[station]
+	if !ok {
+		return errors.New("there isn't a team in that alliance station")
+	}
+	delete(field.AllianceStationToTeam, station)
+	field.removeFromStation(station, teamNum)
+	return nil
+}
+
+// Sends a team's driverstation back to waiting if it's connected in the station
+func (field *Field) removeFromStation(station AllianceStation, teamNum int) {
+	driverStation := field.getDriverStationByTeamNum(teamNum)
+	if driverStation == nil || driverStation.Status == WAITING || driverStation.Station != station {
+		return
+	}
+	driverStation.Status = WAITING
+	driverStation.SendStationInfo()
+	driverStation.sendControlPacketIfChanged()
+	field.logEvent(fmt.Sprintf("Team %d was removed from %s", teamNum, station))
+}
+
+// Moves a connected driverstation's team to another alliance station.
+// A team that was already in the station swaps into the moved team's old station, or waits for a station if it didn't have one.
+func (field *Field) MoveDriverStation(teamNum int, station AllianceStation) error {
+	field.mutex.Lock()
+	defer field.mutex.Unlock()
+	if station < RED1 || station > BLUE3 {
+		return errors.New("that alliance station doesn't exist")
+	}
+	driverStation := field.getDriverStationByTeamNum(teamNum)
+	if driverStation == nil {
+		return errors.New("that team's driverstation isn't connected")
+	}
+	oldStation, hadStation := field.getAllianceStationFromTeamNum(teamNum)
+	if hadStation {
+		delete(field.AllianceStationToTeam, oldStation)
+	}
+	if otherTeamNum, ok := field.AllianceStationToTeam[station]; ok && otherTeamNum != teamNum {
+		if hadStation {
+			field.AllianceStationToTeam[oldStation] = otherTeamNum
+			if otherDriverStation := field.getDriverStationByTeamNum(otherTeamNum); otherDriverStation != nil {
+				otherDriverStation.placeInStation(oldStation)
+			}
+		} else {
+			field.removeFromStation(station, otherTeamNum)
+		}
+	}
+	field.AllianceStationToTeam[station] = teamNum
+	driverStation.placeInStation(station)
+	field.logEvent(fmt.Sprintf("Team %d was moved to %s", teamNum, station))
+	return nil
+}
+
+// Changes the tournament level, used to enter and leave match test mode
+func (field *Field) SetMatchLevel(level Level) {
+	field.mutex.Lock()
+	defer field.mutex.Unlock()
+	field.MatchLevel = level
+	// Changing the timing in the middle of a match would make the clock jump
+	if field.MatchState != STARTED && field.MatchState != PAUSED {
+		field.Timing = GetTimingProfile(level)
+	}
+}
+
+// Changes the event name sent to the driverstations, it should be the event's code
+func (field *Field) SetEventName(eventName string) {
+	field.mutex.Lock()
+	defer field.mutex.Unlock()
+	field.EventName = eventName
+	for _, driverStation := range field.TeamNumberToDriverStation {
+		driverStation.SendEventName()
+	}
+}
+
+// Updates an alliance's scoring data
+func (field *Field) UpdateScoringData(alliance Alliance, data map[string]interface{}) error {
+	field.mutex.Lock()
+	defer field.mutex.Unlock()
+	if err := field.checkScoresEditable(); err != nil {
+		return err
+	}
+	if alliance == RED {
+		field.Scorer.UpdateRedScoringData(data)
+	} else {
+		field.Scorer.UpdateBlueScoringData(data)
+	}
+	return nil
+}
+
+// Adds a foul committed by an alliance
+func (field *Field) AddFoul(alliance Alliance, isTechFoul bool) error {
+	field.mutex.Lock()
+	defer field.mutex.Unlock()
+	if err := field.checkScoresEditable(); err != nil {
+		return err
+	}
+	var fouls, techFouls int
+	if alliance == RED {
+		fouls, techFouls = field.Scorer.GetFoulsRed()
+	} else {
+		fouls, techFouls = field.Scorer.GetFoulsBlue()
+	}
+	if isTechFoul {
+		techFouls++
+	} else {
+		fouls++
+	}
+	if alliance == RED {
+		field.Scorer.SetFoulsRed(fouls, techFouls)
 	} else {
-		// TODO: Switch this to initiate a review
-		field.MatchState = DONE
+		field.Scorer.SetFoulsBlue(fouls, techFouls)
 	}
 	return nil
 }
 
+// Encodes the field as JSON while it's locked, use this instead of encoding the field directly
+func (field *Field) ToJSON() (json.RawMessage, error) {
+	field.mutex.Lock()
+	defer field.mutex.Unlock()
+	return json.Marshal(field)
+}
+
+// Gets a copy of the teams in each alliance station
+func (field *Field) GetAllianceStationToTeam() map[AllianceStation]int {
+	field.mutex.Lock()
+	defer field.mutex.Unlock()
+	allianceStationToTeam := make(map[AllianceStation]int)
+	for station, teamNum := range field.AllianceStationToTeam {
+		allianceStationToTeam[station] = teamNum
+	}
+	return allianceStationToTeam
+}
+
 // Get a driverstation by it's team number
 func (field *Field) GetDriverStationByTeamNum(teamNum int) *DriverStation {
+	field.mutex.Lock()
+	defer field.mutex.Unlock()
+	return field.getDriverStationByTeamNum(teamNum)
+}
+
+func (field *Field) getDriverStationByTeamNum(teamNum int) *DriverStation {
 	if val, ok := field.TeamNumberToDriverStation[teamNum]; ok {
 		return val
 	}
@@ -124,6 +367,8 @@ func (field *Field) GetDriverStationByTeamNum(teamNum int) *DriverStation {
 
 // Get a driverstation by it's IP
 func (field *Field) GetDriverStationByIP(ip net.Addr) *DriverStation {
+	field.mutex.Lock()
+	defer field.mutex.Unlock()
 	for _, driverStation := range field.TeamNumberToDriverStation {
 		if driverStation.TCPSocket.RemoteAddr() == ip {
 			return driverStation
@@ -132,18 +377,30 @@ func (field *Field) GetDriverStationByIP(ip net.Addr) *DriverStation {
 	return nil
 }
 
-// Get a driverstation by it's team number
-func (field *Field) GetAllianceStationFromTeamNum(teamNum int) AllianceStation {
+// Get the alliance station a team is in, ok is false if the team isn't in the match
+func (field *Field) GetAllianceStationFromTeamNum(teamNum int) (station AllianceStation, ok bool) {
+	field.mutex.Lock()
+	defer field.mutex.Unlock()
+	return field.getAllianceStationFromTeamNum(teamNum)
+}
+
+func (field *Field) getAllianceStationFromTeamNum(teamNum int) (AllianceStation, bool) {
 	for allianceStation, team := range field.AllianceStationToTeam {
 		if team == teamNum {
-			return allianceStation
+			return allianceStation, true
 		}
 	}
-	return 0 // Returns RED1
+	return 0, false
 }
 
 // Check if a team is in the match
 func (field *Field) IsTeamInMatch(teamNum int) bool {
+	field.mutex.Lock()
+	defer field.mutex.Unlock()
+	return field.isTeamInMatch(teamNum)
+}
+
+func (field *Field) isTeamInMatch(teamNum int) bool {
 	for _, team := range field.AllianceStationToTeam {
 		if team == teamNum {
 			return true
@@ -152,17 +409,36 @@ func (field *Field) IsTeamInMatch(teamNum int) bool {
 	return false
 }
 
-// Disable all robots
+// Disable all robots, pausing the match if it's running
 func (field *Field) DisableAllRobots() {
-	field.MatchState = PAUSED
+	field.mutex.Lock()
+	defer field.mutex.Unlock()
+	if field.MatchState == STARTED {
+		field.pauseField("all robots disabled")
+		return
+	}
+	field.disableAllRobots()
+}
+
+func (field *Field) disableAllRobots() {
 	for _, driverStation := range field.TeamNumberToDriverStation {
 		driverStation.Enabled = false
 	}
+	field.sendChangedControlPackets()
 }
 
-// Enable all robots
+// Enable all robots, resuming the match if it's paused
 func (field *Field) EnableAllRobots() {
-	field.MatchState = STARTED
+	field.mutex.Lock()
+	defer field.mutex.Unlock()
+	if field.MatchState == PAUSED {
+		field.resumeField()
+		return
+	}
+	field.enableAllRobots()
+}
+
+func (field *Field) enableAllRobots() {
 	for _, teamNum := range field.AllianceStationToTeam {
 		driverStation, ok := field.TeamNumberToDriverStation[teamNum]
 		if !ok {
@@ -170,22 +446,85 @@ func (field *Field) EnableAllRobots() {
 		}
 		driverStation.Enabled = true
 	}
+	field.sendChangedControlPackets()
+}
+
+// Pauses a running match for a field fault, disabling every robot and freezing the clock
+func (field *Field) PauseField(reason string) error {
+	field.mutex.Lock()
+	defer field.mutex.Unlock()
+	if field.MatchState != STARTED {
+		return errors.New("the match isn't running")
+	}
+	return field.pauseField(reason)
+}
+
+func (field *Field) pauseField(reason string) error {
+	if err := field.transitionTo(PAUSED); err != nil {
+		return err
+	}
+	field.disableAllRobots()
+	field.PausedAt = field.Clock.Now()
+	field.PauseReason = reason
+	field.logEvent(fmt.Sprintf("Match paused with %s left: %s", field.matchTimeLeft(field.PausedAt), reason))
+	return nil
+}
+
+// Resumes a paused match in the phase it was paused in
+func (field *Field) ResumeField() error {
+	field.mutex.Lock()
+	defer field.mutex.Unlock()
+	if field.MatchState != PAUSED {
+		return errors.New("the match isn't paused")
+	}
+	return field.resumeField()
+}
+
+func (field *Field) resumeField() error {
+	pausedFor := field.Clock.Now().Sub(field.PausedAt)
+	if err := field.transitionTo(STARTED); err != nil {
+		return err
+	}
+	field.pausedFor += pausedFor
+	field.enableAllRobots()
+	field.CurrentPhase = field.Timing.getPhase(field.matchElapsed(field.Clock.Now()))
+	playPhaseCue(field.CurrentPhase)
+	field.logEvent(fmt.Sprintf("Match resumed after %s with %s left", pausedFor.Round(time.Millisecond), field.matchTimeLeft(field.Clock.Now())))
+	field.PauseReason = ""
+	return nil
 }
 
 // Kicks all driverstations from the FMS
 func (field *Field) KickAllDriverStations() {
+	field.mutex.Lock()
+	defer field.mutex.Unlock()
+	field.kickAllDriverStations()
+}
+
+func (field *Field) kickAllDriverStations() {
 	for _, driverStation := range field.TeamNumberToDriverStation {
-		driverStation.Kick()
+		driverStation.kickWithReason("kicked by the FMS")
 	}
 }
 
 // Checks if all teams are online
 func (field *Field) AllTeamsOnField() bool {
+	field.mutex.Lock()
+	defer field.mutex.Unlock()
+	return field.allTeamsOnField()
+}
+
+func (field *Field) allTeamsOnField() bool {
 	hasAllTeamsOnField := true
-	for _, teamNum := range field.AllianceStationToTeam {
+	for station, teamNum := range field.AllianceStationToTeam {
+		// A bypassed station doesn't need a driverstation
+		if field.BypassedStations[station] {
+			continue
+		}
 		teamIsOnField := false
 		for _, driverStation := range field.TeamNumberToDriverStation {
-			if driverStation.TeamNumber == teamNum {
+			// A driverstation in the wrong station has to be moved before the match can start
+			if driverStation.TeamNumber == teamNum && driverStation.Status == GOOD {
 				teamIsOnField = true
 			}
 		}
@@ -199,64 +538,97 @@ func (field *Field) AllTeamsOnField() bool {
 	return hasAllTeamsOnField
 }
 
-// This is the timer for the field, it ticks every second
+// This is the timer for the field, it checks the match clock every few milliseconds and is frozen while the match is paused.
+// Robots are sent a control packet as soon as they need to be enabled or disabled, instead of waiting for the next one.
 func (field *Field) fieldTimer() {
 	for {
+		field.mutex.Lock()
 		if field.MatchState == STARTED {
-			if field.TimeLeft > TransitionLength+TeleopLength+EndgameLength + 1 {
-				if field.CurrentPhase != AUTONOMOUS {
-					go PlayWAV("audio/CHARGE.wav", time.Millisecond*1500)
-				}
-				field.CurrentPhase = AUTONOMOUS
-			} else if field.TimeLeft > TeleopLength+EndgameLength + 1 {
-				if field.CurrentPhase != TRANSITION {
-					go PlayWAV("audio/ENDMATCH.wav", time.Millisecond*500)
-				}
-				field.CurrentPhase = TRANSITION
-			} else if field.TimeLeft > EndgameLength + 1 {
-				if field.CurrentPhase != TELEOP {
-					go PlayWAV("audio/three-bells.wav", time.Millisecond*1500)
-				}
-				field.CurrentPhase = TELEOP
-			} else if field.TimeLeft > 1 {
-				if field.CurrentPhase != ENDGAME {
-					go PlayWAV("audio/warning.wav", time.Millisecond*3000)
-				}
-				field.CurrentPhase = ENDGAME
-			} else {
-				field.TimeLeft--
-				go PlayWAV("audio/ENDMATCH.wav", time.Millisecond*1000)
-				field.StopField(false)
-				continue
-			}
-			field.TimeLeft--
+			field.updateClock()
 		}
-		time.Sleep(time.Second)
+		field.sendChangedControlPackets()
+		clock := field.Clock
+		field.mutex.Unlock()
+		clock.Sleep(clockResolution)
+	}
+}
+
+// Sends a control packet to every driverstation at the rate set in config.json
+func (field *Field) sendControlPackets() {
+	interval := time.Duration(config.DefaultConfig.Network.ControlPacketInterval) * time.Millisecond
+	if interval <= 0 {
+		interval = time.Millisecond * 250
+	}
+	for {
+		field.mutex.Lock()
+		for _, driverStation := range field.TeamNumberToDriverStation {
+			driverStation.sendControlPacket()
+		}
+		field.mutex.Unlock()
+		time.Sleep(interval)
+	}
+}
+
+// Sends a control packet right away to every driverstation whose robot should be doing something different
+func (field *Field) sendChangedControlPackets() {
+	for _, driverStation := range field.TeamNumberToDriverStation {
+		driverStation.sendControlPacketIfChanged()
 	}
 }
 
 // This is the field's tick loop, it ticks every 500 ms
 func (field *Field) tick() {
 	for {
+		field.mutex.Lock()
 		// Check if all teams are on field in order to say that the game is ready.
-		if field.AllTeamsOnField() && field.MatchState != STARTED && field.MatchState != DONE &&  field.MatchState != PAUSED && field.MatchState != INREVIEW {
-			field.MatchState = READY
+		allTeamsOnField := field.allTeamsOnField()
+		if allTeamsOnField && field.MatchState == NOTREADY {
+			field.transitionTo(READY)
+		} else if !allTeamsOnField && field.MatchState == READY {
+			field.transitionTo(NOTREADY)
 		}
 		for _, driverStation := range field.TeamNumberToDriverStation {
 			driverStation.tick()
 		}
+		field.sendGameSpecificData()
+		events.Publish("fieldTick", field)
+		field.mutex.Unlock()
 		time.Sleep(time.Millisecond * 500)
 	}
 }
 
+// Asks the scorer if either alliance needs a color and sends it to that alliance's driverstations
+func (field *Field) sendGameSpecificData() {
+	if field.MatchState != STARTED {
+		return
+	}
+	if field.Scorer.ShouldSendColorRed(field.GameSpecificData[RED]) {
+		field.setGameSpecificData(RED, field.Scorer.GetColorRed())
+	}
+	if field.Scorer.ShouldSendColorBlue(field.GameSpecificData[BLUE]) {
+		field.setGameSpecificData(BLUE, field.Scorer.GetColorBlue())
+	}
+}
+
+// Stores the game specific data for an alliance and sends it to every driverstation on it
+func (field *Field) setGameSpecificData(alliance Alliance, data string) {
+	field.GameSpecificData[alliance] = data
+	for _, driverStation := range field.TeamNumberToDriverStation {
+		if driverStation.Status == GOOD && driverStation.Station.Alliance() == alliance {
+			driverStation.SendGameSpecificData(data)
+		}
+	}
+}
+
 // Listens for TCP connections from Driverstations
 func (field *Field) listenTCP() {
-	listener, err := net.Listen("tcp", "10.0.100.5:1750")
+	address := config.DefaultConfig.Network.TCPAddress()
+	listener, err := net.Listen("tcp", address)
 	if err != nil {
 		log.Println("Couldn't start FMS TCP Server: " + err.Error())
 		return
 	}
-	log.Println("The FMS started a TCP Server on 10.0.100.5:1750!")
+	log.Println("The FMS started a TCP Server on " + address + "!")
 	defer listener.Close()
 
 	for {
@@ -271,34 +643,51 @@ func (field *Field) listenTCP() {
 
 // Handles every TCP connection to the FMS
 func (field *Field) handleTCPConnection(conn net.Conn) {
-	// errorNum is used to detect if the tcpConn had closed remotely. TODO: Check if there is a better way to do this.
-	errorNum := 0
+	defer conn.Close()
+	var driverStation *DriverStation
 	for {
-		if errorNum > 3 {
-			conn.Close()
+		frame, err := readTCPFrame(conn)
+		if err != nil {
+			field.mutex.Lock()
+			if driverStation != nil && field.getDriverStationByTeamNum(driverStation.TeamNumber) == driverStation {
+				driverStation.kickWithReason("TCP connection closed: " + err.Error())
+			}
+			field.mutex.Unlock()
 			return
 		}
-		var bytes [5]byte
-		_, err := conn.Read(bytes[:])
+		message, err := decodeTCPMessage(frame)
 		if err != nil {
-			errorNum++
+			log.Println("Couldn't decode a frame from " + conn.RemoteAddr().String() + ": " + err.Error())
 			continue
 		}
-		if bytes[2] == 0x18 {
-			teamNum := (int(bytes[3]) << 8) + int(bytes[4])
+		if teamNumberMessage, ok := message.(TeamNumberMessage); ok && driverStation == nil {
 			ipAddress, _, err := net.SplitHostPort(conn.RemoteAddr().String())
 			if err != nil {
 				continue
 			}
-			udpConn, err := net.Dial("udp4", fmt.Sprintf("%s:%d", ipAddress, 1121))
-			field.createDriverStation(teamNum, conn, udpConn)
+			udpConn, err := net.Dial("udp4", net.JoinHostPort(ipAddress, strconv.Itoa(config.DefaultConfig.Network.DriverStationUDPPort)))
+			if err != nil {
+				log.Println("Couldn't open a UDP connection to " + ipAddress + ": " + err.Error())
+				continue
+			}
+			field.mutex.Lock()
+			driverStation = field.createDriverStation(teamNumberMessage.TeamNumber, conn, udpConn)
+			field.mutex.Unlock()
+			continue
+		}
+		if driverStation == nil {
+			continue
 		}
+		field.mutex.Lock()
+		driverStation.receiveTCP(message)
+		field.mutex.Unlock()
 	}
 }
 
 // Listens for UDP messages from Driverstations
 func (field *Field) listenUDP() {
-	udpAddr, err := net.ResolveUDPAddr("udp4", "10.0.100.5:1160")
+	address := config.DefaultConfig.Network.UDPAddress()
+	udpAddr, err := net.ResolveUDPAddr("udp4", address)
 	if err != nil {
 		log.Println("Bad Address for UDP: " + err.Error())
 		return
@@ -310,9 +699,11 @@ func (field *Field) listenUDP() {
 		return
 	}
 
-	log.Println("The FMS started a UDP Server on 10.0.100.5:1160!")
+	log.Println("The FMS started a UDP Server on " + address + "!")
 
+	field.mutex.Lock()
 	field.UDPSocket = listener
+	field.mutex.Unlock()
 
 	defer listener.Close()
 
@@ -325,6 +716,7 @@ func (field *Field) listenUDP() {
 
 func (field *Field) handleUDPMessage(bytes [50]byte) {
 	eStopped := (int(bytes[3]) >> 7 & 0x01) == 1
+	aStopped := (int(bytes[3]) >> 6 & 0x01) == 1
 	comms := (int(bytes[3]) >> 5 & 0x01) == 1
 	radioPing := (int(bytes[3]) >> 4 & 0x01) == 1
 	rioPing := (int(bytes[3]) >> 3 & 0x01) == 1
@@ -333,7 +725,9 @@ func (field *Field) handleUDPMessage(bytes [50]byte) {
 	teamNum := (int(bytes[4]) << 8) + int(bytes[5])
 	batteryVoltage := float64(bytes[6]) + float64(bytes[7])/256
 
-	if driverStation := field.GetDriverStationByTeamNum(teamNum); driverStation != nil {
-		driverStation.receiveUDP(eStopped, comms, radioPing, rioPing, enabled, mode, batteryVoltage)
+	field.mutex.Lock()
+	defer field.mutex.Unlock()
+	if driverStation := field.getDriverStationByTeamNum(teamNum); driverStation != nil {
+		driverStation.receiveUDP(eStopped, aStopped, comms, radioPing, rioPing, enabled, mode, batteryVoltage)
 	}
 }
\ No newline at end of file
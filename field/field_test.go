@@ -0,0 +1,175 @@
+package field
+
+import (
+	"io"
+	"io/ioutil"
+	"net"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/McMackety/nevermore/config"
+)
+
+// The test field listens on it's own ports so the tests can run next to a real FMS
+var testNetwork = config.NetworkConfig{
+	DevelopmentMode:       true,
+	BindAddress:           "127.0.0.1",
+	TCPPort:               21750,
+	UDPPort:               21160,
+	DriverStationUDPPort:  21121,
+	ControlPacketInterval: 50,
+}
+
+var networkFieldOnce sync.Once
+var networkField *Field
+
+// Starts a field that simulated driverstations can connect to.
+// It's networking can't be stopped, so every test shares the same one.
+func startNetworkField() *Field {
+	networkFieldOnce.Do(func() {
+		config.DefaultConfig.Network = testNetwork
+		networkField = newField()
+		go networkField.fieldTimer()
+		go networkField.tick()
+		go networkField.sendControlPackets()
+		go networkField.listenTCP()
+		go networkField.listenUDP()
+	})
+	return networkField
+}
+
+// Gets the field's state while it's locked
+func (field *Field) getMatchState() State {
+	field.mutex.Lock()
+	defer field.mutex.Unlock()
+	return field.MatchState
+}
+
+// Fails the test if condition isn't true within the timeout
+func waitFor(t *testing.T, timeout time.Duration, description string, condition func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", description)
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+}
+
+// Connects a driverstation for a team without any networking, everything sent to it is thrown away
+func connectFakeDriverStation(t *testing.T, field *Field, teamNum int) *DriverStation {
+	t.Helper()
+	fmsSide, driverStationSide := net.Pipe()
+	go io.Copy(ioutil.Discard, driverStationSide)
+	udpConn, err := net.Dial("udp4", "127.0.0.1:9")
+	if err != nil {
+		t.Fatal(err)
+	}
+	field.mutex.Lock()
+	defer field.mutex.Unlock()
+	return field.createDriverStation(teamNum, fmsSide, udpConn)
+}
+
+func TestMoveDriverStationTakesTheStationsStops(t *testing.T) {
+	field := newField()
+	field.AllianceStationToTeam[RED2] = 1
+	field.EmergencyStoppedStations[RED1] = true
+	field.AutonomousStoppedStations[RED1] = true
+	field.DisabledStations[BLUE2] = true
+	driverStation := connectFakeDriverStation(t, field, 1)
+
+	tests := []struct {
+		station          AllianceStation
+		emergencyStopped bool
+		autonomousStop   bool
+		disabled         bool
+	}{
+		{RED1, true, true, false},
+		{BLUE2, false, false, true},
+		{RED2, false, false, false},
+	}
+	for _, test := range tests {
+		if err := field.MoveDriverStation(1, test.station); err != nil {
+			t.Fatal(err)
+		}
+		field.mutex.Lock()
+		if driverStation.EmergencyStopped != test.emergencyStopped || driverStation.AutonomousStopped != test.autonomousStop || driverStation.Disabled != test.disabled {
+			t.Errorf("after moving to %s expected e-stop %t, a-stop %t and disabled %t, got %t, %t and %t", test.station,
+				test.emergencyStopped, test.autonomousStop, test.disabled, driverStation.EmergencyStopped, driverStation.AutonomousStopped, driverStation.Disabled)
+		}
+		field.mutex.Unlock()
+	}
+}
+
+func TestMoveDriverStationKeepsTheStationsInStep(t *testing.T) {
+	field := newField()
+	field.AllianceStationToTeam[RED1] = 1
+	field.AllianceStationToTeam[BLUE1] = 2
+	driverStation := connectFakeDriverStation(t, field, 1)
+	otherDriverStation := connectFakeDriverStation(t, field, 2)
+	waitingDriverStation := connectFakeDriverStation(t, field, 3)
+
+	if field.MoveDriverStation(1, 42) == nil {
+		t.Error("expected moving to a station that doesn't exist to fail")
+	}
+	if err := field.MoveDriverStation(1, BLUE1); err != nil {
+		t.Fatal(err)
+	}
+	field.mutex.Lock()
+	if field.AllianceStationToTeam[BLUE1] != 1 || field.AllianceStationToTeam[RED1] != 2 || driverStation.Station != BLUE1 || otherDriverStation.Station != RED1 {
+		t.Errorf("expected teams 1 and 2 to swap stations, got %v", field.AllianceStationToTeam)
+	}
+	field.mutex.Unlock()
+
+	// A team without a station takes the place of the team in it, which has to wait for a station
+	if err := field.MoveDriverStation(3, RED1); err != nil {
+		t.Fatal(err)
+	}
+	field.mutex.Lock()
+	defer field.mutex.Unlock()
+	if field.AllianceStationToTeam[RED1] != 3 || waitingDriverStation.Status != GOOD || otherDriverStation.Status != WAITING {
+		t.Errorf("expected team 3 to replace team 2 in %s, got %v with team 3 %d and team 2 %d", RED1, field.AllianceStationToTeam,
+			waitingDriverStation.Status, otherDriverStation.Status)
+	}
+	if _, ok := field.getAllianceStationFromTeamNum(2); ok {
+		t.Error("expected team 2 not to have a station anymore")
+	}
+}
+
+func TestAddingAndRemovingTeamsPlacesConnectedDriverStations(t *testing.T) {
+	field := newField()
+	driverStation := connectFakeDriverStation(t, field, 7)
+	otherDriverStation := connectFakeDriverStation(t, field, 8)
+	if driverStation.Status != WAITING {
+		t.Fatalf("expected a team that isn't in the match to be waiting, it's status %d", driverStation.Status)
+	}
+
+	if err := field.AddTeam(RED3, 7); err != nil {
+		t.Fatal(err)
+	}
+	field.mutex.Lock()
+	if driverStation.Status != GOOD || driverStation.Station != RED3 || !field.allTeamsOnField() {
+		t.Errorf("expected team 7 to be placed in %s, it's status %d in %s", RED3, driverStation.Status, driverStation.Station)
+	}
+	field.mutex.Unlock()
+
+	if err := field.AddTeam(RED3, 8); err != nil {
+		t.Fatal(err)
+	}
+	field.mutex.Lock()
+	if driverStation.Status != WAITING || otherDriverStation.Status != GOOD || otherDriverStation.Station != RED3 {
+		t.Errorf("expected team 8 to replace team 7 in %s", RED3)
+	}
+	field.mutex.Unlock()
+
+	if err := field.RemoveTeamByStation(RED3); err != nil {
+		t.Fatal(err)
+	}
+	field.mutex.Lock()
+	defer field.mutex.Unlock()
+	if otherDriverStation.Status != WAITING || field.getDriverStationByTeamNum(8) != otherDriverStation {
+		t.Errorf("expected team 8 to stay connected and wait for a station, it's status %d", otherDriverStation.Status)
+	}
+}
@@ -0,0 +1,93 @@
+package field
+
+import "time"
+
+// The most entries of each kind kept for a single team in a match
+const maxHistoryEntries = 2000
+
+// DriverStationHistory is everything a driverstation reported during the current match, kept even after it disconnects
+type DriverStationHistory struct {
+	TeamNumber  int               `json:"teamNum"`
+	Telemetry   []TelemetryEntry  `json:"telemetry"`
+	Messages    []MessageEntry    `json:"messages"`
+	Connections []ConnectionEntry `json:"connections"`
+}
+
+// TelemetryEntry is a single log data report from a driverstation
+type TelemetryEntry struct {
+	Time           time.Time `json:"time"`
+	TripTime       float64   `json:"tripTime"`
+	LostPackets    int       `json:"lostPackets"`
+	BatteryVoltage float64   `json:"batteryVoltage"`
+	Brownout       bool      `json:"brownout"`
+	Watchdog       bool      `json:"watchdog"`
+	CPUUtilization float64   `json:"cpuUtilization"`
+	CANUtilization float64   `json:"canUtilization"`
+	SignalDB       int       `json:"signalDB"`
+	Bandwidth      float64   `json:"bandwidth"`
+}
+
+// MessageEntry is a single error or warning from the robot program
+type MessageEntry struct {
+	Time    time.Time `json:"time"`
+	Code    int       `json:"code"`
+	IsError bool      `json:"isError"`
+	Details string    `json:"details"`
+}
+
+// ConnectionEntry records a driverstation connecting or disconnecting
+type ConnectionEntry struct {
+	Time      time.Time `json:"time"`
+	Connected bool      `json:"connected"`
+	Reason    string    `json:"reason"`
+}
+
+// Get a copy of the history of a team for the current match
+func (field *Field) GetDriverStationHistory(teamNum int) *DriverStationHistory {
+	field.mutex.Lock()
+	defer field.mutex.Unlock()
+	if history, ok := field.DriverStationHistories[teamNum]; ok {
+		return &DriverStationHistory{
+			TeamNumber:  history.TeamNumber,
+			Telemetry:   append([]TelemetryEntry(nil), history.Telemetry...),
+			Messages:    append([]MessageEntry(nil), history.Messages...),
+			Connections: append([]ConnectionEntry(nil), history.Connections...),
+		}
+	}
+	return nil
+}
+
+// Gets the history for a team, creating it if it doesn't exist yet
+func (field *Field) getHistory(teamNum int) *DriverStationHistory {
+	history, ok := field.DriverStationHistories[teamNum]
+	if !ok {
+		history = &DriverStationHistory{TeamNumber: teamNum}
+		field.DriverStationHistories[teamNum] = history
+	}
+	return history
+}
+
+func (history *DriverStationHistory) addTelemetry(entry TelemetryEntry) {
+	if len(history.Telemetry) >= maxHistoryEntries {
+		history.Telemetry = history.Telemetry[1:]
+	}
+	history.Telemetry = append(history.Telemetry, entry)
+}
+
+func (history *DriverStationHistory) addMessage(entry MessageEntry) {
+	if len(history.Messages) >= maxHistoryEntries {
+		history.Messages = history.Messages[1:]
+	}
+	history.Messages = append(history.Messages, entry)
+}
+
+func (history *DriverStationHistory) addConnection(connected bool, reason string) {
+	if len(history.Connections) >= maxHistoryEntries {
+		history.Connections = history.Connections[1:]
+	}
+	history.Connections = append(history.Connections, ConnectionEntry{
+		Time:      time.Now(),
+		Connected: connected,
+		Reason:    reason,
+	})
+}
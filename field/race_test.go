@@ -0,0 +1,83 @@
+package field
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/McMackety/nevermore/database"
+	"github.com/McMackety/nevermore/database/testutil"
+)
+
+// Plays a match with simulated driverstations while the same things the web API does run alongside the field timer.
+// It's meant to be run with -race.
+func TestConcurrentMatch(t *testing.T) {
+	testutil.UseTestDatabase(t)
+	field := startNetworkField()
+	if err := field.SetupField(2, PRACTICE, 11, 12, 13, 14, 15, 16); err != nil {
+		t.Fatal(err)
+	}
+	sim := connectSimulator(t, []int{11, 12, 13, 14, 15, 16}, "127.0.0.21")
+	defer sim.Close()
+	waitFor(t, time.Second*3, "the field to be ready", func() bool {
+		return field.getMatchState() == READY
+	})
+
+	stop := make(chan bool)
+	var waitGroup sync.WaitGroup
+	repeat := func(action func()) {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			for {
+				select {
+				case <-stop:
+					return
+				default:
+					action()
+					time.Sleep(time.Millisecond)
+				}
+			}
+		}()
+	}
+	repeat(func() { field.ToJSON() })
+	repeat(func() { field.GetAllianceStationToTeam() })
+	repeat(func() { field.GetDriverStationHistory(11) })
+	repeat(func() { field.AddFoul(RED, false) })
+	repeat(func() { field.UpdateScoringData(BLUE, map[string]interface{}{"autoCellsBottom": 1}) })
+	repeat(func() { sim.DriverStations[0].SetBatteryVoltage(11.5) })
+	repeat(func() { sim.DriverStations[1].LastControlPacket() })
+
+	if err := field.StartField(); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(time.Millisecond * 200)
+	if err := field.PauseField("race test"); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(time.Millisecond * 100)
+	if err := field.ResumeField(); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(time.Millisecond * 200)
+	if err := field.StopField(false); err != nil {
+		t.Fatal(err)
+	}
+
+	repeat(func() { field.GetReview() })
+	repeat(func() { field.SetFouls(RED, 1, 0) })
+	repeat(func() { field.SetCard(RED1, YELLOWCARD) })
+	time.Sleep(time.Millisecond * 50)
+	if err := field.CommitReview(); err != nil {
+		t.Fatal(err)
+	}
+	close(stop)
+	waitGroup.Wait()
+
+	if state := field.getMatchState(); state != DONE {
+		t.Fatalf("expected the match to be done after committing it, it's %s", state)
+	}
+	if results := database.GetMatchResults(int(PRACTICE)); len(results) != 1 {
+		t.Fatalf("expected one practice result, got %d", len(results))
+	}
+}
@@ -0,0 +1,205 @@
+package field
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/McMackety/nevermore/bracket"
+	"github.com/McMackety/nevermore/database"
+	"github.com/McMackety/nevermore/events"
+	"github.com/McMackety/nevermore/rankings"
+	"log"
+	"time"
+)
+
+// Review is everything the head referee sees while reviewing a match
+type Review struct {
+	MatchNumber           int                      `json:"matchNum"`
+	MatchLevel            Level                    `json:"matchLevel"`
+	AllianceStationToTeam map[AllianceStation]int  `json:"allianceStationToTeam"`
+	RedScoringData        map[string]interface{}   `json:"redScoringData"`
+	BlueScoringData       map[string]interface{}   `json:"blueScoringData"`
+	RedScore              int                      `json:"redScore"`
+	BlueScore             int                      `json:"blueScore"`
+	RedFouls              int                      `json:"redFouls"`
+	RedTechFouls          int                      `json:"redTechFouls"`
+	BlueFouls             int                      `json:"blueFouls"`
+	BlueTechFouls         int                      `json:"blueTechFouls"`
+	Cards                 map[AllianceStation]Card `json:"cards"`
+	StartedAt             time.Time                `json:"startedAt"`
+	EndedAt               time.Time                `json:"endedAt"`
+}
+
+// Gets the match's score breakdown for review, only available once the match is in review
+func (field *Field) GetReview() (Review, error) {
+	field.mutex.Lock()
+	defer field.mutex.Unlock()
+	if field.MatchState != INREVIEW {
+		return Review{}, errors.New("the match isn't in review")
+	}
+	return field.buildReview(), nil
+}
+
+func (field *Field) buildReview() Review {
+	review := Review{
+		MatchNumber:           field.MatchNumber,
+		MatchLevel:            field.MatchLevel,
+		AllianceStationToTeam: make(map[AllianceStation]int),
+		RedScoringData:        field.Scorer.GetScoringDataRed(),
+		BlueScoringData:       field.Scorer.GetScoringDataBlue(),
+		Cards:                 make(map[AllianceStation]Card),
+		StartedAt:             field.MatchStartedAt,
+		EndedAt:               field.MatchEndedAt,
+	}
+	for station, teamNum := range field.AllianceStationToTeam {
+		review.AllianceStationToTeam[station] = teamNum
+	}
+	for station, card := range field.Cards {
+		review.Cards[station] = card
+	}
+	// The committed score counts the points from the other alliance's fouls
+	redScore, blueScore := field.Scorer.GetFinalScore()
+	redFoulPoints, blueFoulPoints := field.Scorer.GetFoulPoints()
+	review.RedScore, review.BlueScore = redScore+redFoulPoints, blueScore+blueFoulPoints
+	review.RedFouls, review.RedTechFouls = field.Scorer.GetFoulsRed()
+	review.BlueFouls, review.BlueTechFouls = field.Scorer.GetFoulsBlue()
+	return review
+}
+
+// Scores, fouls and cards can only be changed while the match is being played or reviewed
+func (field *Field) checkScoresEditable() error {
+	if field.MatchState == DONE || field.committing {
+		return errors.New("the match's result has already been committed")
+	}
+	if field.MatchState != STARTED && field.MatchState != PAUSED && field.MatchState != INREVIEW {
+		return errors.New("the match hasn't started")
+	}
+	return nil
+}
+
+// Sets the number of fouls an alliance committed, this can be changed until the result is committed
+func (field *Field) SetFouls(alliance Alliance, fouls int, techFouls int) error {
+	field.mutex.Lock()
+	defer field.mutex.Unlock()
+	if err := field.checkScoresEditable(); err != nil {
+		return err
+	}
+	if fouls < 0 || techFouls < 0 {
+		return errors.New("the number of fouls can't be negative")
+	}
+	if alliance == RED {
+		field.Scorer.SetFoulsRed(fouls, techFouls)
+	} else {
+		field.Scorer.SetFoulsBlue(fouls, techFouls)
+	}
+	return nil
+}
+
+// Gives the team in an alliance station a card, this can be changed until the result is committed
+func (field *Field) SetCard(station AllianceStation, card Card) error {
+	field.mutex.Lock()
+	defer field.mutex.Unlock()
+	if err := field.checkScoresEditable(); err != nil {
+		return err
+	}
+	if station < RED1 || station > BLUE3 {
+		return errors.New("that alliance station doesn't exist")
+	}
+	if card < NOCARD || card > REDCARD {
+		return errors.New("that card doesn't exist")
+	}
+	if card == NOCARD {
+		delete(field.Cards, station)
+	} else {
+		field.Cards[station] = card
+	}
+	field.logEvent(fmt.Sprintf("%s given to %s", card, station))
+	return nil
+}
+
+// Finishes reviewing the match, saving the result and publishing it as the matchResultCommitted event.
+// The field is only locked to copy the review out and to move to DONE, saving it goes through the database and
+// can be slow. If saving fails the match stays in review so it can be committed again.
+func (field *Field) CommitReview() error {
+	field.mutex.Lock()
+	if field.MatchState != INREVIEW {
+		field.mutex.Unlock()
+		return errors.New("the match isn't in review")
+	}
+	if field.committing {
+		field.mutex.Unlock()
+		return errors.New("the match's result is already being committed")
+	}
+	field.committing = true
+	review := field.buildReview()
+	committedAt := field.Clock.Now()
+	field.mutex.Unlock()
+
+	err := saveReview(review, committedAt)
+
+	field.mutex.Lock()
+	defer field.mutex.Unlock()
+	field.committing = false
+	if err != nil {
+		return err
+	}
+	if err := field.transitionTo(DONE); err != nil {
+		return err
+	}
+	events.Publish("matchResultCommitted", review)
+	return nil
+}
+
+// Saves a reviewed match's result, then updates the schedule and the rankings or bracket it counts towards
+func saveReview(review Review, committedAt time.Time) error {
+	redScoringData, err := json.Marshal(review.RedScoringData)
+	if err != nil {
+		return err
+	}
+	blueScoringData, err := json.Marshal(review.BlueScoringData)
+	if err != nil {
+		return err
+	}
+	cards, err := json.Marshal(review.Cards)
+	if err != nil {
+		return err
+	}
+	result := database.MatchResult{
+		MatchNumber:     review.MatchNumber,
+		Level:           int(review.MatchLevel),
+		Red1:            review.AllianceStationToTeam[RED1],
+		Red2:            review.AllianceStationToTeam[RED2],
+		Red3:            review.AllianceStationToTeam[RED3],
+		Blue1:           review.AllianceStationToTeam[BLUE1],
+		Blue2:           review.AllianceStationToTeam[BLUE2],
+		Blue3:           review.AllianceStationToTeam[BLUE3],
+		RedScore:        review.RedScore,
+		BlueScore:       review.BlueScore,
+		RedFouls:        review.RedFouls,
+		RedTechFouls:    review.RedTechFouls,
+		BlueFouls:       review.BlueFouls,
+		BlueTechFouls:   review.BlueTechFouls,
+		RedScoringData:  string(redScoringData),
+		BlueScoringData: string(blueScoringData),
+		Cards:           string(cards),
+		StartedAt:       review.StartedAt,
+		EndedAt:         review.EndedAt,
+		CommittedAt:     committedAt,
+	}
+	if err := database.CreateMatchResult(&result); err != nil {
+		return errors.New("couldn't save the match result: " + err.Error())
+	}
+	if err := database.MarkScheduledMatchPlayed(int(review.MatchLevel), review.MatchNumber); err != nil {
+		log.Println("Couldn't mark the match as played in the schedule: " + err.Error())
+	}
+	if review.MatchLevel == QUALIFICATION {
+		if err := rankings.Update(review.MatchNumber); err != nil {
+			log.Println("Couldn't update the rankings: " + err.Error())
+		}
+	} else if review.MatchLevel == PLAYOFF {
+		if _, err := bracket.Advance(); err != nil {
+			log.Println("Couldn't advance the playoff bracket: " + err.Error())
+		}
+	}
+	return nil
+}
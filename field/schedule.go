@@ -0,0 +1,14 @@
+package field
+
+import (
+	"github.com/McMackety/nevermore/database"
+)
+
+// Sets up the field with the next match in the schedule for a level that hasn't been played yet
+func (field *Field) SetupNextMatch(level Level) error {
+	match, err := database.GetNextScheduledMatch(int(level))
+	if err != nil {
+		return err
+	}
+	return field.SetupField(match.Number, level, match.Red1, match.Red2, match.Red3, match.Blue1, match.Blue2, match.Blue3)
+}
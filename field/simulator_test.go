@@ -0,0 +1,95 @@
+package field
+
+import (
+	"testing"
+	"time"
+
+	"github.com/McMackety/nevermore/database"
+	"github.com/McMackety/nevermore/database/testutil"
+	"github.com/McMackety/nevermore/simulator"
+)
+
+// Connects simulated driverstations for the teams, retrying while the field's TCP server starts up
+func connectSimulator(t *testing.T, teams []int, baseIP string) *simulator.Simulator {
+	t.Helper()
+	var sim *simulator.Simulator
+	var err error
+	for attempt := 0; attempt < 20; attempt++ {
+		sim, err = simulator.CreateSimulator(teams, baseIP)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = sim.Connect(testNetwork.BindAddress, testNetwork.TCPPort, testNetwork.UDPPort, testNetwork.DriverStationUDPPort); err == nil {
+			return sim
+		}
+		time.Sleep(time.Millisecond * 50)
+	}
+	t.Fatalf("couldn't connect the simulator: %s", err)
+	return nil
+}
+
+// Checks every simulated driverstation's last control packet
+func allControlPackets(sim *simulator.Simulator, check func(packet simulator.ControlPacket) bool) bool {
+	for _, driverStation := range sim.DriverStations {
+		if !check(driverStation.LastControlPacket()) {
+			return false
+		}
+	}
+	return true
+}
+
+func TestSimulatedMatch(t *testing.T) {
+	testutil.UseTestDatabase(t)
+	field := startNetworkField()
+	if err := field.SetupField(1, QUALIFICATION, 1, 2, 3, 4, 5, 6); err != nil {
+		t.Fatal(err)
+	}
+	sim := connectSimulator(t, []int{1, 2, 3, 4, 5, 6}, "127.0.0.11")
+	defer sim.Close()
+
+	waitFor(t, time.Second*3, "every driverstation to be placed", func() bool {
+		for i, driverStation := range sim.DriverStations {
+			station, status := driverStation.StationInfo()
+			if station != i || status != int(GOOD) {
+				return false
+			}
+		}
+		return true
+	})
+	waitFor(t, time.Second*3, "the field to be ready", func() bool {
+		return field.getMatchState() == READY
+	})
+
+	if err := field.StartField(); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, time.Second*2, "every robot to be enabled in autonomous", func() bool {
+		return allControlPackets(sim, func(packet simulator.ControlPacket) bool {
+			return packet.Enabled && packet.Autonomous
+		})
+	})
+
+	if err := field.StopField(false); err != nil {
+		t.Fatal(err)
+	}
+	if state := field.getMatchState(); state != INREVIEW {
+		t.Fatalf("expected the match to be in review after stopping, it's %s", state)
+	}
+	waitFor(t, time.Second*2, "every robot to be disabled", func() bool {
+		return allControlPackets(sim, func(packet simulator.ControlPacket) bool {
+			return !packet.Enabled
+		})
+	})
+
+	history := field.GetDriverStationHistory(1)
+	if history == nil || len(history.Connections) == 0 {
+		t.Fatal("expected team 1's connection to be in it's history")
+	}
+
+	if err := field.CommitReview(); err != nil {
+		t.Fatal(err)
+	}
+	if results := database.GetMatchResults(int(QUALIFICATION)); len(results) != 1 || results[0].Red1 != 1 {
+		t.Fatalf("expected the match's result to be saved, got %+v", results)
+	}
+}
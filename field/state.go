@@ -0,0 +1,57 @@
+package field
+
+import (
+	"fmt"
+	"github.com/McMackety/nevermore/events"
+	"log"
+	"time"
+)
+
+// The states the field is allowed to move to from each state
+var stateTransitions = map[State][]State{
+	NOTREADY: {READY},
+	READY:    {NOTREADY, STARTED},
+	STARTED:  {PAUSED, INREVIEW, DONE},
+	PAUSED:   {STARTED, INREVIEW, DONE},
+	INREVIEW: {DONE},
+	DONE:     {NOTREADY},
+}
+
+// StateTransition is published as the matchStateChanged event whenever the field changes state
+type StateTransition struct {
+	From State     `json:"from"`
+	To   State     `json:"to"`
+	Time time.Time `json:"time"`
+}
+
+// Checks if the field can move from one state to another
+func CanTransition(from State, to State) bool {
+	for _, allowed := range stateTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// Moves the field to a new state, returning an error if it isn't allowed from the current state
+func (field *Field) transitionTo(state State) error {
+	if !CanTransition(field.MatchState, state) {
+		return fmt.Errorf("the field can't go from %s to %s", field.MatchState, state)
+	}
+	transition := StateTransition{
+		From: field.MatchState,
+		To:   state,
+		Time: field.Clock.Now(),
+	}
+	field.MatchState = state
+	field.logEvent(fmt.Sprintf("Match state changed from %s to %s", transition.From, transition.To))
+	events.Publish("matchStateChanged", transition)
+	return nil
+}
+
+// Adds a line to the match log and prints it
+func (field *Field) logEvent(message string) {
+	log.Println(message)
+	field.Log = append(field.Log, field.Clock.Now().Format("15:04:05.000")+" "+message)
+}
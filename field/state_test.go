@@ -0,0 +1,117 @@
+package field
+
+import (
+	"testing"
+
+	"github.com/McMackety/nevermore/database/testutil"
+)
+
+func TestCanTransition(t *testing.T) {
+	allowed := map[State][]State{
+		NOTREADY: {READY},
+		READY:    {NOTREADY, STARTED},
+		STARTED:  {PAUSED, INREVIEW, DONE},
+		PAUSED:   {STARTED, INREVIEW, DONE},
+		INREVIEW: {DONE},
+		DONE:     {NOTREADY},
+	}
+	states := []State{NOTREADY, READY, STARTED, PAUSED, INREVIEW, DONE}
+	for _, from := range states {
+		for _, to := range states {
+			expected := false
+			for _, state := range allowed[from] {
+				if state == to {
+					expected = true
+				}
+			}
+			if CanTransition(from, to) != expected {
+				t.Errorf("CanTransition(%s, %s) should be %t", from, to, expected)
+			}
+		}
+	}
+}
+
+func TestStateChanges(t *testing.T) {
+	testutil.UseTestDatabase(t)
+	setup := func(field *Field) error {
+		return field.SetupField(1, PRACTICE, 1, 2, 3, 4, 5, 6)
+	}
+	start := func(field *Field) error { return field.StartField() }
+	pause := func(field *Field) error { return field.PauseField("testing") }
+	resume := func(field *Field) error { return field.ResumeField() }
+	stop := func(field *Field) error { return field.StopField(false) }
+	stopEarly := func(field *Field) error { return field.StopField(true) }
+	commit := func(field *Field) error { return field.CommitReview() }
+
+	tests := []struct {
+		name     string
+		from     State
+		action   func(field *Field) error
+		expected State
+		fails    bool
+	}{
+		{"start when not ready", NOTREADY, start, NOTREADY, true},
+		{"start when ready", READY, start, STARTED, false},
+		{"start when started", STARTED, start, STARTED, true},
+		{"start when paused", PAUSED, start, PAUSED, true},
+		{"start when in review", INREVIEW, start, INREVIEW, true},
+		{"start when done", DONE, start, DONE, true},
+		{"pause when ready", READY, pause, READY, true},
+		{"pause when started", STARTED, pause, PAUSED, false},
+		{"pause when paused", PAUSED, pause, PAUSED, true},
+		{"resume when started", STARTED, resume, STARTED, true},
+		{"resume when paused", PAUSED, resume, STARTED, false},
+		{"resume when in review", INREVIEW, resume, INREVIEW, true},
+		{"stop when ready", READY, stop, READY, true},
+		{"stop when started", STARTED, stop, INREVIEW, false},
+		{"stop when paused", PAUSED, stop, INREVIEW, false},
+		{"stop early when started", STARTED, stopEarly, DONE, false},
+		{"stop early when paused", PAUSED, stopEarly, DONE, false},
+		{"stop when in review", INREVIEW, stop, INREVIEW, true},
+		{"stop early when in review", INREVIEW, stopEarly, INREVIEW, true},
+		{"commit when started", STARTED, commit, STARTED, true},
+		{"commit when paused", PAUSED, commit, PAUSED, true},
+		{"commit when in review", INREVIEW, commit, DONE, false},
+		{"commit when done", DONE, commit, DONE, true},
+		{"setup when ready", READY, setup, NOTREADY, false},
+		{"setup when started", STARTED, setup, STARTED, true},
+		{"setup when paused", PAUSED, setup, PAUSED, true},
+		{"setup when in review", INREVIEW, setup, INREVIEW, true},
+		{"setup when done", DONE, setup, NOTREADY, false},
+	}
+	for _, test := range tests {
+		field := newField()
+		field.MatchState = test.from
+		field.PausedAt = field.Clock.Now()
+		err := test.action(field)
+		if test.fails && err == nil {
+			t.Errorf("%s: expected an error", test.name)
+		} else if !test.fails && err != nil {
+			t.Errorf("%s: %s", test.name, err)
+		}
+		if field.MatchState != test.expected {
+			t.Errorf("%s: expected the field to be %s, it's %s", test.name, test.expected, field.MatchState)
+		}
+	}
+}
+
+func TestScoresOnlyChangeDuringMatch(t *testing.T) {
+	editable := map[State]bool{STARTED: true, PAUSED: true, INREVIEW: true}
+	for _, state := range []State{NOTREADY, READY, STARTED, PAUSED, INREVIEW, DONE} {
+		field := newField()
+		field.MatchState = state
+		errs := []error{
+			field.UpdateScoringData(RED, map[string]interface{}{}),
+			field.AddFoul(BLUE, true),
+			field.SetFouls(RED, 1, 1),
+			field.SetCard(BLUE1, YELLOWCARD),
+		}
+		for _, err := range errs {
+			if editable[state] && err != nil {
+				t.Errorf("expected scores to be editable when %s: %s", state, err)
+			} else if !editable[state] && err == nil {
+				t.Errorf("expected scores to be locked when %s", state)
+			}
+		}
+	}
+}
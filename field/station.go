@@ -0,0 +1,85 @@
+package field
+
+import (
+	"fmt"
+	"github.com/McMackety/nevermore/config"
+	"github.com/McMackety/nevermore/events"
+	"log"
+	"net"
+)
+
+// WrongStation is published as the wrongStation event when a driverstation is plugged into another team's station
+type WrongStation struct {
+	TeamNumber       int             `json:"teamNum"`
+	AssignedStation  AllianceStation `json:"assignedStation"`
+	PluggedInStation AllianceStation `json:"pluggedInStation"`
+}
+
+// Parses the station subnets from the config, a station without a subnet is never checked
+func loadStationSubnets() map[AllianceStation]*net.IPNet {
+	subnets := make(map[AllianceStation]*net.IPNet)
+	for station := RED1; station <= BLUE3; station++ {
+		cidr, ok := config.DefaultConfig.Network.StationSubnets[station.String()]
+		if !ok {
+			continue
+		}
+		_, subnet, err := net.ParseCIDR(cidr)
+		if err != nil {
+			log.Panicln("The subnet for " + station.String() + " (" + cidr + ") isn't valid, the FMS can't startup!")
+		}
+		subnets[station] = subnet
+	}
+	return subnets
+}
+
+// Gets the team subnet (10.TE.AM.0/24) that a team's driverstation is given on the field network
+func teamSubnet(teamNum int) *net.IPNet {
+	return &net.IPNet{
+		IP:   net.IPv4(10, byte(teamNum/100), byte(teamNum%100), 0),
+		Mask: net.CIDRMask(24, 32),
+	}
+}
+
+// Works out which station a driverstation is plugged into from it's address
+func (field *Field) pluggedInStation(addr net.Addr) (AllianceStation, bool) {
+	host, _, err := net.SplitHostPort(addr.String())
+	if err != nil {
+		return 0, false
+	}
+	ip := net.ParseIP(host)
+	if ip == nil {
+		return 0, false
+	}
+	for station := RED1; station <= BLUE3; station++ {
+		subnet, ok := field.stationSubnets[station]
+		if !ok {
+			// Every team is on the same loopback network in development mode, so there is nothing to check
+			teamNum, inMatch := field.AllianceStationToTeam[station]
+			if !inMatch || config.DefaultConfig.Network.DevelopmentMode {
+				continue
+			}
+			subnet = teamSubnet(teamNum)
+		}
+		if subnet.Contains(ip) {
+			return station, true
+		}
+	}
+	return 0, false
+}
+
+// Marks the driverstation BAD if it's plugged into a different station than it's team is assigned to, a BAD driverstation is never enabled
+func (driverStation *DriverStation) checkStation() {
+	field := driverStation.CurrentField
+	driverStation.Status = GOOD
+	pluggedIn, ok := field.pluggedInStation(driverStation.TCPSocket.RemoteAddr())
+	if !ok || pluggedIn == driverStation.Station {
+		return
+	}
+	driverStation.Status = BAD
+	field.logEvent(fmt.Sprintf("Team %d is plugged into %s but is assigned to %s", driverStation.TeamNumber, pluggedIn, driverStation.Station))
+	events.Publish("wrongStation", WrongStation{
+		TeamNumber:       driverStation.TeamNumber,
+		AssignedStation:  driverStation.Station,
+		PluggedInStation: pluggedIn,
+	})
+}
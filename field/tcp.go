@@ -0,0 +1,191 @@
+package field
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+	"math"
+	"time"
+)
+
+// TCPTag is the tag byte that identifies the contents of a driverstation TCP frame
+type TCPTag byte
+
+// The tags a driverstation can send to the FMS over TCP
+const (
+	WPILIBVERSIONTAG TCPTag = 0x00
+	RIOVERSIONTAG    TCPTag = 0x01
+	DSVERSIONTAG     TCPTag = 0x02
+	PDPVERSIONTAG    TCPTag = 0x03
+	PCMVERSIONTAG    TCPTag = 0x04
+	CANJAGVERSIONTAG TCPTag = 0x05
+	TALONVERSIONTAG  TCPTag = 0x06
+	THIRDPARTYTAG    TCPTag = 0x07
+	LOGDATATAG       TCPTag = 0x16
+	ERRORMESSAGETAG  TCPTag = 0x17
+	TEAMNUMBERTAG    TCPTag = 0x18
+	KEEPALIVETAG     TCPTag = 0x1d
+)
+
+// The largest frame the FMS will accept from a driverstation, anything bigger means the stream is garbage.
+const maxTCPFrameSize = 4096
+
+// TCPMessage is a single decoded frame received from a driverstation
+type TCPMessage interface {
+	Tag() TCPTag
+}
+
+// TeamNumberMessage is sent by the driverstation right after connecting
+type TeamNumberMessage struct {
+	TeamNumber int
+}
+
+// KeepAliveMessage is sent by the driverstation to keep the connection open
+type KeepAliveMessage struct{}
+
+// VersionMessage reports the version of a piece of software or hardware on the robot
+type VersionMessage struct {
+	MessageTag TCPTag
+	Name       string
+	Version    string
+}
+
+// LogDataMessage is the periodic telemetry the driverstation sends during a match
+type LogDataMessage struct {
+	TripTime       float64 // Round trip time in milliseconds
+	LostPackets    int
+	BatteryVoltage float64
+	Brownout       bool
+	Watchdog       bool
+	CPUUtilization float64 // Percent
+	CANUtilization float64 // Percent
+	SignalDB       int
+	Bandwidth      float64 // Megabits per second
+}
+
+// ErrorMessage is an error or warning the robot program reported to the driverstation
+type ErrorMessage struct {
+	Timestamp time.Time
+	Sequence  int
+	Code      int
+	IsError   bool
+	Details   string
+}
+
+// UnknownMessage holds any frame the FMS doesn't know how to decode
+type UnknownMessage struct {
+	MessageTag TCPTag
+	Payload    []byte
+}
+
+func (message TeamNumberMessage) Tag() TCPTag { return TEAMNUMBERTAG }
+
+func (message KeepAliveMessage) Tag() TCPTag { return KEEPALIVETAG }
+
+func (message VersionMessage) Tag() TCPTag { return message.MessageTag }
+
+func (message LogDataMessage) Tag() TCPTag { return LOGDATATAG }
+
+func (message ErrorMessage) Tag() TCPTag { return ERRORMESSAGETAG }
+
+func (message UnknownMessage) Tag() TCPTag { return message.MessageTag }
+
+// Reads a single size prefixed frame, this is the inverse of prefixWithSize
+func readTCPFrame(reader io.Reader) ([]byte, error) {
+	var size [2]byte
+	if _, err := io.ReadFull(reader, size[:]); err != nil {
+		return nil, err
+	}
+	length := (int(size[0]) << 8) + int(size[1])
+	if length > maxTCPFrameSize {
+		return nil, errors.New("the driverstation sent a frame that is too large")
+	}
+	frame := make([]byte, length)
+	if _, err := io.ReadFull(reader, frame); err != nil {
+		return nil, err
+	}
+	return frame, nil
+}
+
+// Decodes a frame (without the size prefix) into a typed message
+func decodeTCPMessage(frame []byte) (TCPMessage, error) {
+	if len(frame) == 0 {
+		return nil, errors.New("the driverstation sent an empty frame")
+	}
+	tag := TCPTag(frame[0])
+	payload := frame[1:]
+	switch tag {
+	case TEAMNUMBERTAG:
+		if len(payload) < 2 {
+			return nil, errors.New("the team number frame is too short")
+		}
+		return TeamNumberMessage{TeamNumber: (int(payload[0]) << 8) + int(payload[1])}, nil
+	case KEEPALIVETAG:
+		return KeepAliveMessage{}, nil
+	case WPILIBVERSIONTAG, RIOVERSIONTAG, DSVERSIONTAG, PDPVERSIONTAG, PCMVERSIONTAG, CANJAGVERSIONTAG, TALONVERSIONTAG, THIRDPARTYTAG:
+		return decodeVersionMessage(tag, payload)
+	case LOGDATATAG:
+		return decodeLogDataMessage(payload)
+	case ERRORMESSAGETAG:
+		return decodeErrorMessage(payload)
+	}
+	return UnknownMessage{MessageTag: tag, Payload: payload}, nil
+}
+
+// Version frames are two size prefixed strings, the device name and the version
+func decodeVersionMessage(tag TCPTag, payload []byte) (TCPMessage, error) {
+	name, rest, err := readSizedString(payload)
+	if err != nil {
+		return nil, err
+	}
+	version, _, err := readSizedString(rest)
+	if err != nil {
+		return nil, err
+	}
+	return VersionMessage{MessageTag: tag, Name: name, Version: version}, nil
+}
+
+// Log data frames are laid out as:
+// trip time (ms/2), lost packets, battery voltage (8.8 fixed point), status flags,
+// CPU utilization (%/2), CAN utilization (%/2), signal strength (dB), bandwidth (8.8 fixed point Mb/s)
+func decodeLogDataMessage(payload []byte) (TCPMessage, error) {
+	if len(payload) < 10 {
+		return nil, errors.New("the log data frame is too short")
+	}
+	return LogDataMessage{
+		TripTime:       float64(payload[0]) * 2,
+		LostPackets:    int(payload[1]),
+		BatteryVoltage: float64(payload[2]) + float64(payload[3])/256,
+		Brownout:       payload[4]&0x80 != 0,
+		Watchdog:       payload[4]&0x40 != 0,
+		CPUUtilization: float64(payload[5]) / 2,
+		CANUtilization: float64(payload[6]) / 2,
+		SignalDB:       int(payload[7]),
+		Bandwidth:      float64(payload[8]) + float64(payload[9])/256,
+	}, nil
+}
+
+// Error frames are laid out as:
+// timestamp (float64 seconds since the unix epoch), sequence number (u16), error code (i32), flags, details
+func decodeErrorMessage(payload []byte) (TCPMessage, error) {
+	if len(payload) < 15 {
+		return nil, errors.New("the error message frame is too short")
+	}
+	seconds := math.Float64frombits(binary.BigEndian.Uint64(payload[0:8]))
+	return ErrorMessage{
+		Timestamp: time.Unix(0, int64(seconds*float64(time.Second))),
+		Sequence:  int(binary.BigEndian.Uint16(payload[8:10])),
+		Code:      int(int32(binary.BigEndian.Uint32(payload[10:14]))),
+		IsError:   payload[14]&0x01 != 0,
+		Details:   string(payload[15:]),
+	}, nil
+}
+
+// Reads a string prefixed with a single length byte, returning whatever is left over
+func readSizedString(bytes []byte) (string, []byte, error) {
+	if len(bytes) < 1 || len(bytes) < int(bytes[0])+1 {
+		return "", nil, errors.New("the string in the frame is too short")
+	}
+	length := int(bytes[0])
+	return string(bytes[1 : length+1]), bytes[length+1:], nil
+}
@@ -1,22 +1,72 @@
 package field
 
-// The length of the autonomous period in seconds
-var AutoLength = 15
+import (
+	"github.com/McMackety/nevermore/config"
+	"log"
+	"time"
+)
 
-// The length of the transition period in seconds
-var TransitionLength = 1
+// TimingProfile is how long each period of a match lasts, in seconds
+type TimingProfile struct {
+	AutoLength       int `json:"autoLength"`
+	TransitionLength int `json:"transitionLength"`
+	TeleopLength     int `json:"teleopLength"`
+	EndgameLength    int `json:"endgameLength"`
+}
 
-// The length of the teleop period in seconds
-var TeleopLength = 105
+// The timing of an official match, used for any level without a profile in config.json
+var DefaultTimingProfile = TimingProfile{
+	AutoLength:       15,
+	TransitionLength: 1,
+	TeleopLength:     105,
+	EndgameLength:    30,
+}
 
-// The length of the endgame period in seconds
-var EndgameLength = 30
+// Gets the timing profile for a level from config.json, falling back to DefaultTimingProfile.
+// Any period left out of the profile takes it's length from DefaultTimingProfile.
+func GetTimingProfile(level Level) TimingProfile {
+	profile, ok := config.DefaultConfig.TimingProfiles[level.String()]
+	if !ok {
+		return DefaultTimingProfile
+	}
+	timingProfile := DefaultTimingProfile
+	periods := []struct {
+		name   string
+		config *int
+		length *int
+	}{
+		{"autoLength", profile.AutoLength, &timingProfile.AutoLength},
+		{"transitionLength", profile.TransitionLength, &timingProfile.TransitionLength},
+		{"teleopLength", profile.TeleopLength, &timingProfile.TeleopLength},
+		{"endgameLength", profile.EndgameLength, &timingProfile.EndgameLength},
+	}
+	for _, period := range periods {
+		if period.config == nil {
+			continue
+		}
+		if *period.config < 0 {
+			log.Printf("The timing profile for %s in config.json has a negative %s, using the default timing.", level.String(), period.name)
+			return DefaultTimingProfile
+		}
+		*period.length = *period.config
+	}
+	if timingProfile.MatchLength() == 0 {
+		log.Println("The timing profile for " + level.String() + " in config.json has no time in it, using the default timing.")
+		return DefaultTimingProfile
+	}
+	return timingProfile
+}
+
+// The length of the whole match in seconds
+func (profile TimingProfile) MatchLength() int {
+	return profile.AutoLength + profile.TransitionLength + profile.TeleopLength + profile.EndgameLength
+}
 
 // Returns the time formatted for use in a GUI or the driverstation
-func GetFormattedTime(time int) int {
-	if time > TransitionLength+TeleopLength+EndgameLength {
-		return time - (TransitionLength + TeleopLength + EndgameLength)
-	} else if time > TeleopLength+EndgameLength {
+func (profile TimingProfile) GetFormattedTime(time int) int {
+	if time > profile.TransitionLength+profile.TeleopLength+profile.EndgameLength {
+		return time - (profile.TransitionLength + profile.TeleopLength + profile.EndgameLength)
+	} else if time > profile.TeleopLength+profile.EndgameLength {
 		return 0
 	} else if time > 0 {
 		return time
@@ -24,3 +74,41 @@ func GetFormattedTime(time int) int {
 		return 0
 	}
 }
+
+// The length of the whole match
+func (profile TimingProfile) MatchDuration() time.Duration {
+	return time.Duration(profile.MatchLength()) * time.Second
+}
+
+// Gets the phase of the match from how much of it has been played, NOTHING means it hasn't started or is over
+func (profile TimingProfile) getPhase(elapsed time.Duration) Phase {
+	autoEnd := time.Duration(profile.AutoLength) * time.Second
+	transitionEnd := autoEnd + time.Duration(profile.TransitionLength)*time.Second
+	teleopEnd := transitionEnd + time.Duration(profile.TeleopLength)*time.Second
+	if elapsed < 0 {
+		return NOTHING
+	} else if elapsed < autoEnd {
+		return AUTONOMOUS
+	} else if elapsed < transitionEnd {
+		return TRANSITION
+	} else if elapsed < teleopEnd {
+		return TELEOP
+	} else if elapsed < profile.MatchDuration() {
+		return ENDGAME
+	}
+	return NOTHING
+}
+
+// Plays the sound for the start of a phase
+func playPhaseCue(phase Phase) {
+	switch phase {
+	case AUTONOMOUS:
+		go PlayWAV("audio/CHARGE.wav", time.Millisecond*1500)
+	case TRANSITION:
+		go PlayWAV("audio/ENDMATCH.wav", time.Millisecond*500)
+	case TELEOP:
+		go PlayWAV("audio/three-bells.wav", time.Millisecond*1500)
+	case ENDGAME:
+		go PlayWAV("audio/warning.wav", time.Millisecond*3000)
+	}
+}
@@ -0,0 +1,33 @@
+package field
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/McMackety/nevermore/config"
+)
+
+func TestGetTimingProfile(t *testing.T) {
+	tests := []struct {
+		name     string
+		profile  string
+		expected TimingProfile
+	}{
+		{"every period", `{"autoLength": 10, "transitionLength": 0, "teleopLength": 60, "endgameLength": 20}`, TimingProfile{10, 0, 60, 20}},
+		{"left out periods", `{"teleopLength": 60}`, TimingProfile{DefaultTimingProfile.AutoLength, DefaultTimingProfile.TransitionLength, 60, DefaultTimingProfile.EndgameLength}},
+		{"negative period", `{"autoLength": -1, "teleopLength": 60}`, DefaultTimingProfile},
+		{"no time", `{"autoLength": 0, "transitionLength": 0, "teleopLength": 0, "endgameLength": 0}`, DefaultTimingProfile},
+	}
+	oldProfiles := config.DefaultConfig.TimingProfiles
+	defer func() { config.DefaultConfig.TimingProfiles = oldProfiles }()
+	for _, test := range tests {
+		var profile config.TimingProfileConfig
+		if err := json.Unmarshal([]byte(test.profile), &profile); err != nil {
+			t.Fatal(err)
+		}
+		config.DefaultConfig.TimingProfiles = map[string]config.TimingProfileConfig{PRACTICE.String(): profile}
+		if timingProfile := GetTimingProfile(PRACTICE); timingProfile != test.expected {
+			t.Errorf("%s: expected %+v, got %+v", test.name, test.expected, timingProfile)
+		}
+	}
+}
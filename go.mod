@@ -8,4 +8,5 @@ require (
 	github.com/gorilla/websocket v1.4.1
 	github.com/jinzhu/gorm v1.9.12
 	github.com/mitchellh/mapstructure v1.1.2
+	golang.org/x/crypto v0.0.0-20191205180655-e7c4368fe9dd
 )
@@ -2,25 +2,46 @@ package main
 
 import (
 	"bufio"
+	"flag"
 	"fmt"
+	"github.com/McMackety/nevermore/bracket"
 	"github.com/McMackety/nevermore/config"
 	"github.com/McMackety/nevermore/database"
 	"github.com/McMackety/nevermore/field"
+	"github.com/McMackety/nevermore/rankings"
+	"github.com/McMackety/nevermore/schedule"
+	"github.com/McMackety/nevermore/selection"
+	"github.com/McMackety/nevermore/simulator"
+	"github.com/McMackety/nevermore/web"
 	"log"
 	"os"
+	"os/signal"
 	"strconv"
 	"strings"
+	"time"
 )
 
 var Version string = "0.0.1" // This will be injected at build time, don't worry about it :)
 var GitCommit string = "dev" // This will be injected at build time, don't worry about it :)
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "simulate" {
+		runSimulator(os.Args[2:])
+		return
+	}
+
 	log.Printf("Starting nevermore v%s (Commit %s)", Version, GitCommit)
 	config.LoadConfig()
 	field.CreateField()
 	field.CurrentField.Run()
 	database.InitDatabase()
+	if event, err := database.GetCurrentEvent(); err == nil {
+		field.CurrentField.SetEventName(event.Code)
+	}
+	if err := selection.Load(); err != nil {
+		log.Println("Couldn't load alliance selection: " + err.Error())
+	}
+	web.StartServer()
 
 	// CLI app down here, mostly used for pre-gui debugging
 
@@ -45,46 +66,367 @@ func main() {
 			}
 			continue
 		case "stopMatch":
+			err := field.CurrentField.StopField(false)
+			if err != nil {
+				log.Println(err.Error())
+			}
+			continue
+		case "abortMatch":
 			err := field.CurrentField.StopField(true)
 			if err != nil {
 				log.Println(err.Error())
 			}
 			continue
+		case "pauseMatch":
+			if len(parts) < 2 {
+				println("Improper usage of pauseMatch: Usage: pauseMatch <reason>")
+				continue
+			}
+			err := field.CurrentField.PauseField(strings.Join(parts[1:], " "))
+			if err != nil {
+				log.Println(err.Error())
+			}
+			continue
+		case "resumeMatch":
+			err := field.CurrentField.ResumeField()
+			if err != nil {
+				log.Println(err.Error())
+			}
+			continue
+		case "commitReview":
+			err := field.CurrentField.CommitReview()
+			if err != nil {
+				log.Println(err.Error())
+			}
+			continue
+		case "estop":
+			if len(parts) == 2 {
+				if station, err := strconv.Atoi(parts[1]); err == nil {
+					if err := field.CurrentField.EmergencyStopStation(field.AllianceStation(station)); err != nil {
+						log.Println(err.Error())
+					}
+					continue
+				}
+			}
+			println("Improper usage of estop: Usage: estop <station>")
+			continue
+		case "astop":
+			if len(parts) == 2 {
+				if station, err := strconv.Atoi(parts[1]); err == nil {
+					if err := field.CurrentField.AutonomousStopStation(field.AllianceStation(station)); err != nil {
+						log.Println(err.Error())
+					}
+					continue
+				}
+			}
+			println("Improper usage of astop: Usage: astop <station>")
+			continue
+		case "bypass":
+			if len(parts) == 3 {
+				if station, err := strconv.Atoi(parts[1]); err == nil {
+					if bypassed, err := strconv.ParseBool(parts[2]); err == nil {
+						if err := field.CurrentField.SetStationBypassed(field.AllianceStation(station), bypassed); err != nil {
+							log.Println(err.Error())
+						}
+						continue
+					}
+				}
+			}
+			println("Improper usage of bypass: Usage: bypass <station> <true|false>")
+			continue
+		case "disable":
+			if len(parts) == 3 {
+				if station, err := strconv.Atoi(parts[1]); err == nil {
+					if disabled, err := strconv.ParseBool(parts[2]); err == nil {
+						if err := field.CurrentField.SetStationDisabled(field.AllianceStation(station), disabled); err != nil {
+							log.Println(err.Error())
+						}
+						continue
+					}
+				}
+			}
+			println("Improper usage of disable: Usage: disable <station> <true|false>")
+			continue
 		case "startTest":
-			field.CurrentField.MatchLevel = field.MATCHTEST
+			field.CurrentField.SetMatchLevel(field.MATCHTEST)
 			continue
 		case "stopTest":
-			field.CurrentField.MatchLevel = field.PRACTICE
+			field.CurrentField.SetMatchLevel(field.PRACTICE)
 			continue
 		case "addTeam":
-			if station, err := strconv.Atoi(parts[1]); err == nil {
-				if team, err := strconv.Atoi(parts[2]); err == nil {
-					field.CurrentField.AllianceStationToTeam[field.AllianceStation(station)] = team
-					continue
+			if len(parts) == 3 {
+				if station, err := strconv.Atoi(parts[1]); err == nil {
+					if team, err := strconv.Atoi(parts[2]); err == nil {
+						if err := field.CurrentField.AddTeam(field.AllianceStation(station), team); err != nil {
+							log.Println(err.Error())
+						}
+						continue
+					}
 				}
 			}
-			println("Improper usage of enable: Usage: addTeam <station> <teamNum>")
+			println("Improper usage of addTeam: Usage: addTeam <station> <teamNum>")
 			continue
 		case "removeTeamByStation":
-			if station, err := strconv.Atoi(parts[1]); err == nil {
-				if teamNum, ok := field.CurrentField.AllianceStationToTeam[field.AllianceStation(station)]; ok {
-					if driverStation := field.CurrentField.GetDriverStationByTeamNum(teamNum); driverStation != nil {
-						delete(field.CurrentField.AllianceStationToTeam, field.AllianceStation(station))
-						driverStation.Kick()
+			if len(parts) == 2 {
+				if station, err := strconv.Atoi(parts[1]); err == nil {
+					if err := field.CurrentField.RemoveTeamByStation(field.AllianceStation(station)); err != nil {
+						log.Println(err.Error())
+					}
+					continue
+				}
+			}
+			println("Improper usage of removeTeamByStation: Usage: removeTeamByStation <station>")
+			continue
+		case "createUser":
+			if len(parts) == 4 {
+				if userType, err := database.ParseUserType(parts[2]); err == nil {
+					if err := database.CreateUser(parts[1], userType, parts[3]); err != nil {
+						log.Println(err.Error())
+					}
+					continue
+				}
+			}
+			println("Improper usage of createUser: Usage: createUser <username> <ADMIN|FTA|HEADREFEREE|REFEREE|SCORER> <pin>")
+			continue
+		case "removeUser":
+			if len(parts) == 2 {
+				if err := database.DeleteUser(parts[1]); err != nil {
+					log.Println(err.Error())
+				}
+				continue
+			}
+			println("Improper usage of removeUser: Usage: removeUser <username>")
+			continue
+		case "createEvent":
+			if len(parts) >= 2 {
+				event := database.Event{Code: parts[1], Name: strings.Join(parts[2:], " ")}
+				if err := database.CreateEvent(&event); err != nil {
+					log.Println(err.Error())
+				} else {
+					field.CurrentField.SetEventName(event.Code)
+				}
+				continue
+			}
+			println("Improper usage of createEvent: Usage: createEvent <code> [name]")
+			continue
+		case "selectEvent":
+			if len(parts) == 2 {
+				if event, err := database.SelectEvent(parts[1]); err != nil {
+					log.Println(err.Error())
+				} else {
+					field.CurrentField.SetEventName(event.Code)
+				}
+				continue
+			}
+			println("Improper usage of selectEvent: Usage: selectEvent <code>")
+			continue
+		case "createTeam":
+			if len(parts) >= 2 {
+				if teamNum, err := strconv.Atoi(parts[1]); err == nil {
+					if err := database.CreateTeam(&database.Team{Number: teamNum, Name: strings.Join(parts[2:], " ")}); err != nil {
+						log.Println(err.Error())
+					}
+					continue
+				}
+			}
+			println("Improper usage of createTeam: Usage: createTeam <teamNum> [name]")
+			continue
+		case "generateSchedule":
+			if len(parts) == 3 {
+				if matchesPerTeam, err := strconv.Atoi(parts[1]); err == nil {
+					if minTurnaround, err := strconv.Atoi(parts[2]); err == nil {
+						matches, err := schedule.GenerateForTeams(int(field.QUALIFICATION), matchesPerTeam, minTurnaround)
+						if err != nil {
+							log.Println(err.Error())
+						} else {
+							log.Printf("Generated %d qualification matches", len(matches))
+						}
+						continue
+					}
+				}
+			}
+			println("Improper usage of generateSchedule: Usage: generateSchedule <matchesPerTeam> <minTurnaround>")
+			continue
+		case "importTeams":
+			if len(parts) == 2 {
+				if err := schedule.ImportTeamsFile(parts[1]); err != nil {
+					log.Println(err.Error())
+				}
+				continue
+			}
+			println("Improper usage of importTeams: Usage: importTeams <file.csv|file.json>")
+			continue
+		case "exportTeams":
+			if len(parts) == 2 {
+				if err := schedule.ExportTeamsFile(parts[1]); err != nil {
+					log.Println(err.Error())
+				}
+				continue
+			}
+			println("Improper usage of exportTeams: Usage: exportTeams <file.csv|file.json>")
+			continue
+		case "importSchedule":
+			if len(parts) == 3 {
+				if level, err := field.ParseLevel(parts[1]); err == nil {
+					if err := schedule.ImportScheduleFile(int(level), parts[2]); err != nil {
+						log.Println(err.Error())
+					}
+					continue
+				}
+			}
+			println("Improper usage of importSchedule: Usage: importSchedule <PRACTICE|QUALIFICATION|PLAYOFF> <file.csv|file.json>")
+			continue
+		case "exportSchedule":
+			if len(parts) == 3 {
+				if level, err := field.ParseLevel(parts[1]); err == nil {
+					if err := schedule.ExportScheduleFile(int(level), parts[2]); err != nil {
+						log.Println(err.Error())
+					}
+					continue
+				}
+			}
+			println("Improper usage of exportSchedule: Usage: exportSchedule <PRACTICE|QUALIFICATION|PLAYOFF> <file.csv|file.json>")
+			continue
+		case "nextMatch":
+			if len(parts) == 2 {
+				if level, err := field.ParseLevel(parts[1]); err == nil {
+					if err := field.CurrentField.SetupNextMatch(level); err != nil {
+						log.Println(err.Error())
+					}
+					continue
+				}
+			}
+			println("Improper usage of nextMatch: Usage: nextMatch <PRACTICE|QUALIFICATION|PLAYOFF>")
+			continue
+		case "rankings":
+			snapshot, err := rankings.GetLatestSnapshot()
+			if err != nil {
+				log.Println(err.Error())
+				continue
+			}
+			log.Printf("Rankings after qualification match %d:", snapshot.MatchNumber)
+			for _, ranking := range snapshot.Rankings.Rankings {
+				log.Printf("%d. Team %d: %.2f RP, %d-%d-%d", ranking.Rank, ranking.TeamNumber, ranking.RankingScore, ranking.Wins, ranking.Losses, ranking.Ties)
+			}
+			continue
+		case "startSelection":
+			if len(parts) == 3 {
+				if allianceCount, err := strconv.Atoi(parts[1]); err == nil {
+					if teamsPerAlliance, err := strconv.Atoi(parts[2]); err == nil {
+						if err := selection.Start(allianceCount, teamsPerAlliance); err != nil {
+							log.Println(err.Error())
+						}
+						continue
+					}
+				}
+			}
+			println("Improper usage of startSelection: Usage: startSelection <allianceCount> <teamsPerAlliance>")
+			continue
+		case "pick":
+			if len(parts) == 2 {
+				if teamNum, err := strconv.Atoi(parts[1]); err == nil {
+					if currentSelection, err := selection.GetCurrentSelection(); err != nil {
+						log.Println(err.Error())
+					} else if err := currentSelection.Pick(teamNum); err != nil {
+						log.Println(err.Error())
+					}
+					continue
+				}
+			}
+			println("Improper usage of pick: Usage: pick <teamNum>")
+			continue
+		case "accept", "decline":
+			if currentSelection, err := selection.GetCurrentSelection(); err != nil {
+				log.Println(err.Error())
+			} else if err := currentSelection.Respond(parts[0] == "accept"); err != nil {
+				log.Println(err.Error())
+			}
+			continue
+		case "generateBracket":
+			if len(parts) == 2 {
+				if format, err := bracket.ParseFormat(parts[1]); err == nil {
+					if _, err := bracket.Generate(format); err != nil {
+						log.Println(err.Error())
 					}
+					continue
 				}
 			}
-			println("Improper usage of enable: Usage: removeTeamByStation <station>")
+			println("Improper usage of generateBracket: Usage: generateBracket <bestOfThree|doubleElimination>")
 			continue
 		case "station":
-			if out, err := strconv.Atoi(parts[1]); err == nil {
-				if driverStation, ok := field.CurrentField.TeamNumberToDriverStation[out]; ok {
+			if len(parts) == 3 {
+				if teamNum, err := strconv.Atoi(parts[1]); err == nil {
 					if station, err := strconv.Atoi(parts[2]); err == nil {
-						driverStation.Station = field.AllianceStation(station)
+						if err := field.CurrentField.MoveDriverStation(teamNum, field.AllianceStation(station)); err != nil {
+							log.Println(err.Error())
+						}
+						continue
 					}
 				}
 			}
+			println("Improper usage of station: Usage: station <teamNum> <station>")
 			continue
 		}
 	}
 }
+
+// Runs a group of simulated driverstations until interrupted, usage: nevermore simulate -teams 254,1678
+func runSimulator(args []string) {
+	flags := flag.NewFlagSet("simulate", flag.ExitOnError)
+	teams := flags.String("teams", "1,2,3,4,5,6", "comma separated team numbers to simulate")
+	fmsIP := flags.String("fms", "127.0.0.1", "the FMS's IP address")
+	tcpPort := flags.Int("tcpPort", 1750, "the FMS's TCP port")
+	udpPort := flags.Int("udpPort", 1160, "the FMS's UDP port")
+	driverStationUDPPort := flags.Int("driverStationUDPPort", 1121, "the UDP port the driverstations listen on")
+	baseIP := flags.String("baseIP", "127.0.0.11", "the IP of the first driverstation, each one after it counts up")
+	battery := flags.Float64("battery", 12.5, "the battery voltage reported by every robot")
+	eStop := flags.Bool("estop", false, "press the e-stop on every driverstation")
+	radioPing := flags.Bool("radioPing", true, "report that the radio is reachable")
+	rioPing := flags.Bool("rioPing", true, "report that the roboRIO is reachable")
+	interval := flags.Duration("interval", time.Millisecond*20, "how often each driverstation sends a status packet")
+	flags.Parse(args)
+	if *interval <= 0 {
+		log.Fatal("the interval has to be more than 0")
+	}
+
+	var teamNums []int
+	for _, team := range strings.Split(*teams, ",") {
+		teamNum, err := strconv.Atoi(strings.TrimSpace(team))
+		if err != nil {
+			log.Fatalf("%s isn't a team number", team)
+		}
+		teamNums = append(teamNums, teamNum)
+	}
+
+	sim, err := simulator.CreateSimulator(teamNums, *baseIP)
+	if err != nil {
+		log.Fatal(err)
+	}
+	for _, driverStation := range sim.DriverStations {
+		driverStation.StatusInterval = *interval
+		driverStation.SetBatteryVoltage(*battery)
+		driverStation.SetEmergencyStop(*eStop)
+		driverStation.SetPings(true, *radioPing, *rioPing)
+	}
+	if err := sim.Connect(*fmsIP, *tcpPort, *udpPort, *driverStationUDPPort); err != nil {
+		log.Fatal(err)
+	}
+	defer sim.Close()
+
+	interrupt := make(chan os.Signal, 1)
+	signal.Notify(interrupt, os.Interrupt)
+	statusTicker := time.NewTicker(time.Second * 5)
+	defer statusTicker.Stop()
+	for {
+		select {
+		case <-interrupt:
+			return
+		case <-statusTicker.C:
+			for _, driverStation := range sim.DriverStations {
+				station, status := driverStation.StationInfo()
+				packet := driverStation.LastControlPacket()
+				log.Printf("Team %d: station %d status %d enabled %t auto %t time left %d", driverStation.TeamNumber, station, status, packet.Enabled, packet.Autonomous, packet.TimeLeft)
+			}
+		}
+	}
+}
@@ -0,0 +1,226 @@
+package rankings
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/McMackety/nevermore/database"
+	"github.com/McMackety/nevermore/enums"
+	"github.com/McMackety/nevermore/events"
+	"github.com/McMackety/nevermore/scoring"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// The database keeps levels as ints
+const qualificationLevel = int(enums.QUALIFICATION)
+
+// Ranking is a single team's row in the rankings
+type Ranking struct {
+	Rank               int       `json:"rank"`
+	TeamNumber         int       `json:"teamNum"`
+	RankingScore       float64   `json:"rankingScore"` // Average ranking points per match
+	TotalRankingPoints int       `json:"totalRankingPoints"`
+	Tiebreakers        []float64 `json:"tiebreakers"` // Averages, in the order the game checks them
+	Wins               int       `json:"wins"`
+	Losses             int       `json:"losses"`
+	Ties               int       `json:"ties"`
+	Disqualifications  int       `json:"disqualifications"`
+	MatchesPlayed      int       `json:"matchesPlayed"`
+
+	tiebreakerTotals []float64
+}
+
+// Rankings is the whole rankings table
+type Rankings struct {
+	TiebreakerNames []string  `json:"tiebreakerNames"`
+	Rankings        []Ranking `json:"rankings"`
+}
+
+// Works out the rankings from match results.
+// Only the last result of a replayed match counts, a surrogate's match never counts for it
+// and a disqualified team (given a red card) gets no ranking points for the match but it still counts as played.
+// A yellow card stays with a team for the rest of the matches, so a team's second yellow card is a red card.
+func Calculate(results []database.MatchResult, schedule []database.ScheduledMatch, rules scoring.RankingRules) Rankings {
+	surrogates := make(map[int][6]bool)
+	for _, match := range schedule {
+		surrogates[match.Number] = [6]bool{
+			match.Red1Surrogate, match.Red2Surrogate, match.Red3Surrogate,
+			match.Blue1Surrogate, match.Blue2Surrogate, match.Blue3Surrogate,
+		}
+	}
+
+	latest := make(map[int]database.MatchResult)
+	for _, result := range results {
+		if existing, ok := latest[result.MatchNumber]; !ok || !result.CommittedAt.Before(existing.CommittedAt) {
+			latest[result.MatchNumber] = result
+		}
+	}
+
+	// Matches are added in order so yellow cards carry forward to the right matches
+	matchNumbers := make([]int, 0, len(latest))
+	for matchNum := range latest {
+		matchNumbers = append(matchNumbers, matchNum)
+	}
+	sort.Ints(matchNumbers)
+
+	rankingsByTeam := make(map[int]*Ranking)
+	yellowCards := make(map[int]bool)
+	for _, matchNum := range matchNumbers {
+		addResult(rankingsByTeam, yellowCards, latest[matchNum], surrogates[matchNum], rules)
+	}
+
+	rankings := Rankings{TiebreakerNames: rules.GetTiebreakerNames(), Rankings: []Ranking{}}
+	for _, ranking := range rankingsByTeam {
+		if ranking.MatchesPlayed > 0 {
+			ranking.RankingScore = float64(ranking.TotalRankingPoints) / float64(ranking.MatchesPlayed)
+			ranking.Tiebreakers = make([]float64, len(ranking.tiebreakerTotals))
+			for i, total := range ranking.tiebreakerTotals {
+				ranking.Tiebreakers[i] = total / float64(ranking.MatchesPlayed)
+			}
+		}
+		rankings.Rankings = append(rankings.Rankings, *ranking)
+	}
+	sort.Slice(rankings.Rankings, func(i, j int) bool {
+		return ranksAbove(rankings.Rankings[i], rankings.Rankings[j])
+	})
+	for i := range rankings.Rankings {
+		rankings.Rankings[i].Rank = i + 1
+	}
+	return rankings
+}
+
+// Adds a match to the rankings of every team in it, yellowCards is every team that's already been given a yellow card
+func addResult(rankingsByTeam map[int]*Ranking, yellowCards map[int]bool, result database.MatchResult, surrogates [6]bool, rules scoring.RankingRules) {
+	var redScoringData, blueScoringData map[string]interface{}
+	json.Unmarshal([]byte(result.RedScoringData), &redScoringData)
+	json.Unmarshal([]byte(result.BlueScoringData), &blueScoringData)
+	var cards map[string]enums.Card
+	json.Unmarshal([]byte(result.Cards), &cards)
+
+	teams := [6]int{result.Red1, result.Red2, result.Red3, result.Blue1, result.Blue2, result.Blue3}
+	for station, teamNum := range teams {
+		if teamNum == 0 {
+			continue
+		}
+		// A team keeps it's cards even from a match that doesn't count for it
+		card := cards[strconv.Itoa(station)]
+		if card == enums.YELLOWCARD {
+			if yellowCards[teamNum] {
+				card = enums.REDCARD
+			}
+			yellowCards[teamNum] = true
+		}
+		if surrogates[station] {
+			continue
+		}
+		score, opponentScore, scoringData := result.RedScore, result.BlueScore, redScoringData
+		if station >= 3 {
+			score, opponentScore, scoringData = result.BlueScore, result.RedScore, blueScoringData
+		}
+
+		ranking, ok := rankingsByTeam[teamNum]
+		if !ok {
+			ranking = &Ranking{TeamNumber: teamNum}
+			rankingsByTeam[teamNum] = ranking
+		}
+		ranking.MatchesPlayed++
+		tiebreakers := rules.GetTiebreakers(score, opponentScore, scoringData)
+		if ranking.tiebreakerTotals == nil {
+			ranking.tiebreakerTotals = make([]float64, len(tiebreakers))
+		}
+		for i, tiebreaker := range tiebreakers {
+			ranking.tiebreakerTotals[i] += tiebreaker
+		}
+
+		// A disqualified team loses the match no matter the score
+		if card == enums.REDCARD {
+			ranking.Disqualifications++
+			ranking.Losses++
+			continue
+		}
+		ranking.TotalRankingPoints += rules.GetRankingPoints(score, opponentScore, scoringData)
+		if score > opponentScore {
+			ranking.Wins++
+		} else if score < opponentScore {
+			ranking.Losses++
+		} else {
+			ranking.Ties++
+		}
+	}
+}
+
+// Compares the ranking score, then each tiebreaker, then the team number so the order never changes between runs
+func ranksAbove(a Ranking, b Ranking) bool {
+	if a.RankingScore != b.RankingScore {
+		return a.RankingScore > b.RankingScore
+	}
+	for i := 0; i < len(a.Tiebreakers) && i < len(b.Tiebreakers); i++ {
+		if a.Tiebreakers[i] != b.Tiebreakers[i] {
+			return a.Tiebreakers[i] > b.Tiebreakers[i]
+		}
+	}
+	return a.TeamNumber < b.TeamNumber
+}
+
+// Works out the qualification rankings from every committed qualification match
+func GetQualificationRankings() Rankings {
+	return Calculate(database.GetMatchResults(qualificationLevel), database.GetSchedule(qualificationLevel), scoring.CreateRankingRules())
+}
+
+// Recalculates the qualification rankings after a match is committed, saving a snapshot and publishing it as the rankingsUpdated event
+func Update(matchNum int) error {
+	rankings := GetQualificationRankings()
+	rankingsJSON, err := json.Marshal(rankings)
+	if err != nil {
+		return err
+	}
+	snapshot := database.RankingSnapshot{
+		Level:       qualificationLevel,
+		MatchNumber: matchNum,
+		Rankings:    string(rankingsJSON),
+		TakenAt:     time.Now(),
+	}
+	if err := database.CreateRankingSnapshot(&snapshot); err != nil {
+		return err
+	}
+	events.Publish("rankingsUpdated", rankings)
+	return nil
+}
+
+// Snapshot is the qualification rankings as they were right after a match was committed
+type Snapshot struct {
+	MatchNumber int       `json:"matchNum"`
+	TakenAt     time.Time `json:"takenAt"`
+	Rankings    Rankings  `json:"rankings"`
+}
+
+func decodeSnapshot(snapshot database.RankingSnapshot) (Snapshot, error) {
+	decoded := Snapshot{MatchNumber: snapshot.MatchNumber, TakenAt: snapshot.TakenAt}
+	if err := json.Unmarshal([]byte(snapshot.Rankings), &decoded.Rankings); err != nil {
+		return decoded, errors.New("couldn't decode the rankings after match " + strconv.Itoa(snapshot.MatchNumber) + ": " + err.Error())
+	}
+	return decoded, nil
+}
+
+// Gets every saved qualification rankings snapshot, oldest first
+func GetSnapshots() ([]Snapshot, error) {
+	var snapshots []Snapshot
+	for _, snapshot := range database.GetRankingSnapshots(qualificationLevel) {
+		decoded, err := decodeSnapshot(snapshot)
+		if err != nil {
+			return nil, err
+		}
+		snapshots = append(snapshots, decoded)
+	}
+	return snapshots, nil
+}
+
+// Gets the qualification rankings as they were saved after the last committed match
+func GetLatestSnapshot() (Snapshot, error) {
+	snapshot, err := database.GetLatestRankingSnapshot(qualificationLevel)
+	if err != nil {
+		return Snapshot{}, err
+	}
+	return decodeSnapshot(snapshot)
+}
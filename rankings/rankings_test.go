@@ -0,0 +1,109 @@
+package rankings
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/McMackety/nevermore/database"
+	"github.com/McMackety/nevermore/database/testutil"
+	"github.com/McMackety/nevermore/scoring"
+)
+
+// A qualification match red won 110 to 0 with both bonus ranking points, replayed after a 0 to 0 first try
+func testResults() []database.MatchResult {
+	scorer := scoring.CreateScoringInterface()
+	scorer.UpdateRedScoringData(map[string]interface{}{"AutoInitiationLine": 3, "HangingRobots": 3, "PositionControlCompleted": true})
+	redScoringData, _ := json.Marshal(scorer.GetScoringDataRed())
+	blueScoringData, _ := json.Marshal(scorer.GetScoringDataBlue())
+	redScore, blueScore := scorer.GetFinalScore()
+	committedAt := time.Date(2020, time.March, 7, 9, 0, 0, 0, time.UTC)
+	return []database.MatchResult{
+		{
+			MatchNumber: 1, Level: qualificationLevel, Red1: 1, Red2: 2, Red3: 3, Blue1: 4, Blue2: 5, Blue3: 6,
+			RedScoringData: "{}", BlueScoringData: "{}", Cards: "{}", CommittedAt: committedAt,
+		},
+		{
+			MatchNumber: 1, Level: qualificationLevel, Red1: 1, Red2: 2, Red3: 3, Blue1: 4, Blue2: 5, Blue3: 6,
+			RedScore: redScore, BlueScore: blueScore, RedScoringData: string(redScoringData), BlueScoringData: string(blueScoringData),
+			Cards: `{"1":2}`, CommittedAt: committedAt.Add(time.Minute * 10),
+		},
+	}
+}
+
+func TestCalculate(t *testing.T) {
+	schedule := []database.ScheduledMatch{{Number: 1, Level: qualificationLevel, Red3Surrogate: true}}
+	rankings := Calculate(testResults(), schedule, scoring.CreateRankingRules())
+
+	byTeam := make(map[int]Ranking)
+	for _, ranking := range rankings.Rankings {
+		byTeam[ranking.TeamNumber] = ranking
+	}
+	if _, ok := byTeam[3]; ok {
+		t.Error("expected the surrogate's match not to count")
+	}
+	if ranking := byTeam[1]; ranking.Rank != 1 || ranking.TotalRankingPoints != 4 || ranking.Wins != 1 || ranking.MatchesPlayed != 1 {
+		t.Errorf("expected team 1 to be first with 4 ranking points from one win, got %+v", ranking)
+	}
+	if ranking := byTeam[2]; ranking.TotalRankingPoints != 0 || ranking.Disqualifications != 1 || ranking.MatchesPlayed != 1 {
+		t.Errorf("expected team 2 to be disqualified by it's red card, got %+v", ranking)
+	}
+	if ranking := byTeam[4]; ranking.TotalRankingPoints != 0 || ranking.Losses != 1 {
+		t.Errorf("expected team 4 to have lost, got %+v", ranking)
+	}
+}
+
+func TestSecondYellowCardIsARedCard(t *testing.T) {
+	var results []database.MatchResult
+	// Team 1 gets a yellow card in matches 1 and 3, listed out of order
+	for _, matchNum := range []int{3, 1, 2} {
+		cards := "{}"
+		if matchNum != 2 {
+			cards = `{"0":1}`
+		}
+		results = append(results, database.MatchResult{
+			MatchNumber: matchNum, Level: qualificationLevel, Red1: 1, Red2: 2, Red3: 3, Blue1: 4, Blue2: 5, Blue3: 6,
+			RedScore: 10, RedScoringData: "{}", BlueScoringData: "{}", Cards: cards, CommittedAt: time.Now(),
+		})
+	}
+	rankings := Calculate(results, nil, scoring.CreateRankingRules())
+
+	for _, ranking := range rankings.Rankings {
+		if ranking.TeamNumber == 1 && (ranking.Disqualifications != 1 || ranking.Wins != 2 || ranking.Losses != 1) {
+			t.Errorf("expected team 1's second yellow card to disqualify it from match 3, got %+v", ranking)
+		}
+		if ranking.TeamNumber == 2 && ranking.Disqualifications != 0 {
+			t.Errorf("expected team 2 not to be disqualified, got %+v", ranking)
+		}
+	}
+}
+
+func TestSnapshots(t *testing.T) {
+	testutil.UseTestDatabase(t)
+
+	if _, err := GetLatestSnapshot(); err == nil {
+		t.Fatal("expected there to be no snapshot before a match is committed")
+	}
+	for _, result := range testResults() {
+		if err := database.CreateMatchResult(&result); err != nil {
+			t.Fatal(err)
+		}
+		if err := Update(result.MatchNumber); err != nil {
+			t.Fatal(err)
+		}
+	}
+	snapshots, err := GetSnapshots()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(snapshots) != 2 {
+		t.Fatalf("expected a snapshot for each commit, got %d", len(snapshots))
+	}
+	latest, err := GetLatestSnapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if latest.MatchNumber != 1 || len(latest.Rankings.Rankings) != 6 || latest.Rankings.Rankings[0].TeamNumber != 1 {
+		t.Errorf("expected the latest snapshot to have team 1 first, got %+v", latest)
+	}
+}
@@ -0,0 +1,70 @@
+package schedule
+
+import (
+	"github.com/McMackety/nevermore/database"
+	"os"
+)
+
+// Imports a team list from a file, the format comes from it's extension
+func ImportTeamsFile(path string) error {
+	format, err := FormatFromPath(path)
+	if err != nil {
+		return err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	teams, err := ReadTeams(file, format)
+	if err != nil {
+		return err
+	}
+	return ImportTeams(teams)
+}
+
+// Imports the schedule for a level from a file, the format comes from it's extension
+func ImportScheduleFile(level int, path string) error {
+	format, err := FormatFromPath(path)
+	if err != nil {
+		return err
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	matches, err := ReadSchedule(file, format)
+	if err != nil {
+		return err
+	}
+	return ImportSchedule(level, matches)
+}
+
+// Exports the team list to a file, the format comes from it's extension
+func ExportTeamsFile(path string) error {
+	format, err := FormatFromPath(path)
+	if err != nil {
+		return err
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return WriteTeams(file, format, database.GetAllTeams())
+}
+
+// Exports the schedule for a level to a file, the format comes from it's extension
+func ExportScheduleFile(level int, path string) error {
+	format, err := FormatFromPath(path)
+	if err != nil {
+		return err
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	return WriteSchedule(file, format, database.GetSchedule(level))
+}
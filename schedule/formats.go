@@ -0,0 +1,241 @@
+package schedule
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/McMackety/nevermore/database"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Format is a file format that teams and schedules can be imported from and exported to
+type Format string
+
+// The formats that can be imported and exported
+const (
+	CSV  Format = "csv"
+	JSON Format = "json"
+)
+
+// The header of a team list CSV, only the team number is needed
+var teamsHeader = []string{"teamNum", "name", "nickname", "location"}
+
+const requiredTeamsColumns = 1
+
+// The header of a schedule CSV, a surrogate has a * after it's team number.
+// Everything after the teams can be left out, so a CSV with only the teams can still be imported.
+var scheduleHeader = []string{"matchNum", "red1", "red2", "red3", "blue1", "blue2", "blue3", "time", "series", "redAlliance", "blueAlliance", "played"}
+
+// How many columns a schedule CSV needs, the match number and the 6 teams
+const requiredScheduleColumns = 7
+
+// Parses a format from it's name, like "csv"
+func ParseFormat(name string) (Format, error) {
+	switch Format(strings.ToLower(name)) {
+	case CSV:
+		return CSV, nil
+	case JSON:
+		return JSON, nil
+	}
+	return "", errors.New("unknown format " + name + ", it has to be csv or json")
+}
+
+// Works out the format of a file from it's extension
+func FormatFromPath(path string) (Format, error) {
+	return ParseFormat(strings.TrimPrefix(filepath.Ext(path), "."))
+}
+
+// Reads a team list
+func ReadTeams(reader io.Reader, format Format) ([]database.Team, error) {
+	if format == JSON {
+		var teams []database.Team
+		if err := json.NewDecoder(reader).Decode(&teams); err != nil {
+			return nil, errors.New("couldn't decode the teams: " + err.Error())
+		}
+		return teams, nil
+	}
+
+	records, err := readCSV(reader, teamsHeader, requiredTeamsColumns)
+	if err != nil {
+		return nil, err
+	}
+	var teams []database.Team
+	for line, record := range records {
+		teamNum, err := strconv.Atoi(strings.TrimSpace(record["teamNum"]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s isn't a team number", line+2, record["teamNum"])
+		}
+		teams = append(teams, database.Team{Number: teamNum, Name: record["name"], Nickname: record["nickname"], Location: record["location"]})
+	}
+	return teams, nil
+}
+
+// Writes a team list
+func WriteTeams(writer io.Writer, format Format, teams []database.Team) error {
+	if format == JSON {
+		return json.NewEncoder(writer).Encode(teams)
+	}
+
+	csvWriter := csv.NewWriter(writer)
+	csvWriter.Write(teamsHeader)
+	for _, team := range teams {
+		csvWriter.Write([]string{strconv.Itoa(team.Number), team.Name, team.Nickname, team.Location})
+	}
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
+
+// Reads a schedule
+func ReadSchedule(reader io.Reader, format Format) ([]database.ScheduledMatch, error) {
+	if format == JSON {
+		var matches []database.ScheduledMatch
+		if err := json.NewDecoder(reader).Decode(&matches); err != nil {
+			return nil, errors.New("couldn't decode the schedule: " + err.Error())
+		}
+		return matches, nil
+	}
+
+	records, err := readCSV(reader, scheduleHeader, requiredScheduleColumns)
+	if err != nil {
+		return nil, err
+	}
+	var matches []database.ScheduledMatch
+	for line, record := range records {
+		matchNum, err := strconv.Atoi(strings.TrimSpace(record["matchNum"]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s isn't a match number", line+2, record["matchNum"])
+		}
+		var teams [6]int
+		var surrogates [6]bool
+		for station, name := range stationNames {
+			value := strings.TrimSpace(record[name])
+			surrogates[station] = strings.HasSuffix(value, "*")
+			if teams[station], err = strconv.Atoi(strings.TrimSuffix(value, "*")); err != nil {
+				return nil, fmt.Errorf("line %d: %s in %s isn't a team number", line+2, record[name], name)
+			}
+		}
+		scheduledMatch := ToScheduledMatches([]Match{{Number: matchNum, Teams: teams, Surrogates: surrogates}}, 0)[0]
+		if err := readScheduleExtras(&scheduledMatch, record); err != nil {
+			return nil, fmt.Errorf("line %d: %s", line+2, err.Error())
+		}
+		matches = append(matches, scheduledMatch)
+	}
+	return matches, nil
+}
+
+// Reads the optional columns after the teams, a missing or empty column is left as it's zero value
+func readScheduleExtras(scheduledMatch *database.ScheduledMatch, record map[string]string) error {
+	column := func(name string) string {
+		return strings.TrimSpace(record[name])
+	}
+	var err error
+	if value := column("time"); value != "" {
+		if scheduledMatch.Time, err = time.Parse(time.RFC3339, value); err != nil {
+			return fmt.Errorf("%s isn't a time like %s", value, time.RFC3339)
+		}
+	}
+	scheduledMatch.Series = column("series")
+	if value := column("redAlliance"); value != "" {
+		if scheduledMatch.RedAlliance, err = strconv.Atoi(value); err != nil {
+			return fmt.Errorf("%s isn't an alliance number", value)
+		}
+	}
+	if value := column("blueAlliance"); value != "" {
+		if scheduledMatch.BlueAlliance, err = strconv.Atoi(value); err != nil {
+			return fmt.Errorf("%s isn't an alliance number", value)
+		}
+	}
+	if value := column("played"); value != "" {
+		if scheduledMatch.Played, err = strconv.ParseBool(value); err != nil {
+			return fmt.Errorf("%s isn't true or false", value)
+		}
+	}
+	return nil
+}
+
+// Writes a schedule
+func WriteSchedule(writer io.Writer, format Format, matches []database.ScheduledMatch) error {
+	if format == JSON {
+		return json.NewEncoder(writer).Encode(matches)
+	}
+
+	csvWriter := csv.NewWriter(writer)
+	csvWriter.Write(scheduleHeader)
+	for _, scheduledMatch := range matches {
+		match := fromScheduledMatch(scheduledMatch)
+		record := []string{strconv.Itoa(match.Number)}
+		for station, team := range match.Teams {
+			value := strconv.Itoa(team)
+			if match.Surrogates[station] {
+				value += "*"
+			}
+			record = append(record, value)
+		}
+		matchTime := ""
+		if !scheduledMatch.Time.IsZero() {
+			matchTime = scheduledMatch.Time.Format(time.RFC3339)
+		}
+		record = append(record, matchTime, scheduledMatch.Series, strconv.Itoa(scheduledMatch.RedAlliance),
+			strconv.Itoa(scheduledMatch.BlueAlliance), strconv.FormatBool(scheduledMatch.Played))
+		csvWriter.Write(record)
+	}
+	csvWriter.Flush()
+	return csvWriter.Error()
+}
+
+// Reads a CSV and returns every record after the header as a map of column name to value.
+// The columns are found by their names in the header so they can be in any order, columns that aren't in header are ignored
+// and the first required columns of header have to be there.
+func readCSV(reader io.Reader, header []string, required int) ([]map[string]string, error) {
+	csvReader := csv.NewReader(reader)
+	csvReader.FieldsPerRecord = -1
+	csvReader.TrimLeadingSpace = true
+	records, err := csvReader.ReadAll()
+	if err != nil {
+		return nil, errors.New("couldn't read the CSV: " + err.Error())
+	}
+	if len(records) == 0 {
+		return nil, errors.New("the CSV has to start with the header " + strings.Join(header, ","))
+	}
+
+	// The index of each column in the CSV
+	columns := make(map[string]int)
+	for index, name := range records[0] {
+		for _, column := range header {
+			if !strings.EqualFold(strings.TrimSpace(name), column) {
+				continue
+			}
+			if _, ok := columns[column]; ok {
+				return nil, fmt.Errorf("the CSV has the %s column twice", column)
+			}
+			columns[column] = index
+		}
+	}
+	for _, column := range header[:required] {
+		if _, ok := columns[column]; !ok {
+			return nil, fmt.Errorf("the CSV is missing the %s column, the header has to have %s", column, strings.Join(header[:required], ","))
+		}
+	}
+
+	var mapped []map[string]string
+	for line, record := range records[1:] {
+		values := make(map[string]string)
+		for column, index := range columns {
+			if index < len(record) {
+				values[column] = record[index]
+			}
+		}
+		for _, column := range header[:required] {
+			if _, ok := values[column]; !ok {
+				return nil, fmt.Errorf("line %d: it doesn't have a %s column", line+2, column)
+			}
+		}
+		mapped = append(mapped, values)
+	}
+	return mapped, nil
+}
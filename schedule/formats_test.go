@@ -0,0 +1,86 @@
+package schedule
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/McMackety/nevermore/database"
+)
+
+func TestScheduleCSVRoundTrip(t *testing.T) {
+	matches := []database.ScheduledMatch{
+		{
+			Number: 1, Time: time.Date(2020, time.March, 7, 9, 0, 0, 0, time.UTC),
+			Red1: 1, Red2: 2, Red3: 3, Blue1: 4, Blue2: 5, Blue3: 6, Blue3Surrogate: true,
+		},
+		{
+			Number: 2, Red1: 11, Red2: 12, Red3: 13, Blue1: 14, Blue2: 15, Blue3: 16,
+			Series: "QF1", RedAlliance: 1, BlueAlliance: 8, Played: true,
+		},
+	}
+	var csv bytes.Buffer
+	if err := WriteSchedule(&csv, CSV, matches); err != nil {
+		t.Fatal(err)
+	}
+	read, err := ReadSchedule(&csv, CSV)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range matches {
+		if !read[i].Time.Equal(matches[i].Time) {
+			t.Errorf("match %d: expected the time %s, got %s", matches[i].Number, matches[i].Time, read[i].Time)
+		}
+		read[i].Time = matches[i].Time
+		if !reflect.DeepEqual(read[i], matches[i]) {
+			t.Errorf("match %d didn't round trip: expected %+v, got %+v", matches[i].Number, matches[i], read[i])
+		}
+	}
+}
+
+func TestScheduleCSVWithOnlyTeams(t *testing.T) {
+	read, err := ReadSchedule(strings.NewReader("matchNum,red1,red2,red3,blue1,blue2,blue3\n1,1,2,3,4,5,6*\n"), CSV)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(read) != 1 || read[0].Blue3 != 6 || !read[0].Blue3Surrogate || read[0].Series != "" || !read[0].Time.IsZero() {
+		t.Errorf("expected a match with only teams, got %+v", read)
+	}
+}
+
+func TestScheduleCSVColumnsAreReadByName(t *testing.T) {
+	read, err := ReadSchedule(strings.NewReader("Blue1,Blue2,Blue3,Time,Red1,Red2,Red3,Field,MatchNum\n4,5,6,2020-03-07T09:00:00Z,1,2*,3,A,7\n"), CSV)
+	if err != nil {
+		t.Fatal(err)
+	}
+	match := read[0]
+	if match.Number != 7 || match.Red1 != 1 || !match.Red2Surrogate || match.Blue1 != 4 || match.Blue3 != 6 || match.Time.Hour() != 9 {
+		t.Errorf("expected the reordered columns to be read by their names, got %+v", match)
+	}
+}
+
+func TestScheduleCSVWithMissingColumns(t *testing.T) {
+	tests := map[string]string{
+		"a missing station":  "matchNum,red1,red2,red3,blue1,blue2\n1,1,2,3,4,5\n",
+		"a short row":        "matchNum,red1,red2,red3,blue1,blue2,blue3\n1,1,2,3,4,5\n",
+		"no match number":    "red1,red2,red3,blue1,blue2,blue3\n1,2,3,4,5,6\n",
+		"a duplicate column": "matchNum,red1,red2,red3,blue1,blue2,blue3,red1\n1,1,2,3,4,5,6,7\n",
+	}
+	for name, csv := range tests {
+		if _, err := ReadSchedule(strings.NewReader(csv), CSV); err == nil {
+			t.Errorf("expected a CSV with %s to be rejected", name)
+		}
+	}
+}
+
+func TestTeamsCSVColumnsAreReadByName(t *testing.T) {
+	read, err := ReadTeams(strings.NewReader("name,rookieYear,teamNum\nCheesy Poofs,1999,254\n"), CSV)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(read) != 1 || read[0].Number != 254 || read[0].Name != "Cheesy Poofs" {
+		t.Errorf("expected team 254 named Cheesy Poofs, got %+v", read)
+	}
+}
@@ -0,0 +1,238 @@
+package schedule
+
+import (
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+// How many random schedules are tried, the one with the fewest repeat partners and opponents is kept
+var Iterations = 200
+
+// Match is a single generated match, Teams and Surrogates are in RED1, RED2, RED3, BLUE1, BLUE2, BLUE3 order
+type Match struct {
+	Number     int
+	Teams      [6]int
+	Surrogates [6]bool
+}
+
+// pair is two teams, always with the smaller team number first
+type pair [2]int
+
+func makePair(teamA int, teamB int) pair {
+	if teamA > teamB {
+		return pair{teamB, teamA}
+	}
+	return pair{teamA, teamB}
+}
+
+// generator holds the state for building one schedule
+type generator struct {
+	random        *rand.Rand
+	teams         []int
+	minTurnaround int
+	remaining     map[int]int
+	played        map[int]int
+	lastPlayed    map[int]int
+	surrogate     map[int]int // The appearance (counting from 0) that a team plays as a surrogate
+	partners      map[pair]int
+	opponents     map[pair]int
+}
+
+// Generates a qualification schedule where every team plays matchesPerTeam matches.
+// A team never plays in two matches that are less than minTurnaround matches apart.
+// If the teams don't fill the last match evenly, some teams play one more match as a surrogate,
+// like FRC that is their third match.
+func Generate(teams []int, matchesPerTeam int, minTurnaround int) ([]Match, error) {
+	if len(teams) < 6 {
+		return nil, errors.New("a schedule needs at least 6 teams")
+	}
+	if matchesPerTeam < 1 {
+		return nil, errors.New("every team has to play at least 1 match")
+	}
+	if minTurnaround < 0 {
+		return nil, errors.New("the minimum turnaround can't be negative")
+	}
+	seen := make(map[int]bool)
+	for _, team := range teams {
+		if seen[team] {
+			return nil, fmt.Errorf("team %d is in the list twice", team)
+		}
+		seen[team] = true
+	}
+	if 6*(minTurnaround+1) > len(teams) {
+		return nil, fmt.Errorf("%d teams isn't enough for a turnaround of %d matches", len(teams), minTurnaround)
+	}
+
+	random := rand.New(rand.NewSource(time.Now().UnixNano()))
+	var best []Match
+	bestScore := 0
+	for i := 0; i < Iterations; i++ {
+		generator := newGenerator(random, teams, matchesPerTeam, minTurnaround)
+		matches, ok := generator.generate()
+		if !ok {
+			continue
+		}
+		if score := generator.score(); best == nil || score < bestScore {
+			best = matches
+			bestScore = score
+		}
+	}
+	if best == nil {
+		return nil, fmt.Errorf("couldn't make a schedule with a turnaround of %d matches, try a smaller turnaround", minTurnaround)
+	}
+	return best, nil
+}
+
+func newGenerator(random *rand.Rand, teams []int, matchesPerTeam int, minTurnaround int) *generator {
+	generator := &generator{
+		random:        random,
+		teams:         teams,
+		minTurnaround: minTurnaround,
+		remaining:     make(map[int]int),
+		played:        make(map[int]int),
+		lastPlayed:    make(map[int]int),
+		surrogate:     make(map[int]int),
+		partners:      make(map[pair]int),
+		opponents:     make(map[pair]int),
+	}
+	for _, team := range teams {
+		generator.remaining[team] = matchesPerTeam
+		generator.lastPlayed[team] = -minTurnaround - 1
+	}
+
+	// Random teams fill out the last match as surrogates
+	surrogateCount := (6 - len(teams)*matchesPerTeam%6) % 6
+	surrogateAppearance := matchesPerTeam
+	if surrogateAppearance > 2 {
+		surrogateAppearance = 2
+	}
+	for _, index := range random.Perm(len(teams))[:surrogateCount] {
+		generator.remaining[teams[index]]++
+		generator.surrogate[teams[index]] = surrogateAppearance
+	}
+	return generator
+}
+
+// Builds the matches one at a time, ok is false if the turnaround couldn't be honored
+func (generator *generator) generate() (matches []Match, ok bool) {
+	slots := 0
+	for _, remaining := range generator.remaining {
+		slots += remaining
+	}
+	for number := 0; number < slots/6; number++ {
+		var candidates []int
+		for _, team := range generator.teams {
+			if generator.remaining[team] > 0 && number-generator.lastPlayed[team] > generator.minTurnaround {
+				candidates = append(candidates, team)
+			}
+		}
+		if len(candidates) < 6 {
+			return nil, false
+		}
+		matches = append(matches, generator.buildMatch(number, generator.pickTeams(candidates)))
+	}
+	return matches, true
+}
+
+// Picks the 6 teams for a match, teams with the most matches left go first so nobody is left over at the end
+func (generator *generator) pickTeams(candidates []int) []int {
+	var picked []int
+	for len(picked) < 6 {
+		bestIndex := -1
+		bestCost := 0
+		for index, team := range candidates {
+			cost := -generator.remaining[team] * 1000
+			for _, other := range picked {
+				pair := makePair(team, other)
+				cost += (generator.partners[pair] + generator.opponents[pair]) * 10
+			}
+			cost += generator.random.Intn(10)
+			if bestIndex == -1 || cost < bestCost {
+				bestIndex = index
+				bestCost = cost
+			}
+		}
+		picked = append(picked, candidates[bestIndex])
+		candidates = append(candidates[:bestIndex], candidates[bestIndex+1:]...)
+	}
+	return picked
+}
+
+// Splits the teams into alliances with the fewest repeat partners and opponents, then records the match
+func (generator *generator) buildMatch(number int, picked []int) Match {
+	generator.random.Shuffle(len(picked), func(i, j int) { picked[i], picked[j] = picked[j], picked[i] })
+
+	var bestRed, bestBlue []int
+	bestCost := -1
+	// picked[0] is always red, so each split is only tried once
+	for a := 1; a < 6; a++ {
+		for b := a + 1; b < 6; b++ {
+			red := []int{picked[0], picked[a], picked[b]}
+			var blue []int
+			for index, team := range picked {
+				if index != 0 && index != a && index != b {
+					blue = append(blue, team)
+				}
+			}
+			if cost := generator.allianceCost(red, blue); bestCost == -1 || cost < bestCost {
+				bestRed, bestBlue, bestCost = red, blue, cost
+			}
+		}
+	}
+
+	match := Match{Number: number + 1}
+	for index, team := range append(bestRed, bestBlue...) {
+		match.Teams[index] = team
+		if appearance, ok := generator.surrogate[team]; ok && appearance == generator.played[team] {
+			match.Surrogates[index] = true
+		}
+		generator.remaining[team]--
+		generator.played[team]++
+		generator.lastPlayed[team] = number
+	}
+	for _, alliance := range [][]int{bestRed, bestBlue} {
+		for i := 0; i < len(alliance); i++ {
+			for j := i + 1; j < len(alliance); j++ {
+				generator.partners[makePair(alliance[i], alliance[j])]++
+			}
+		}
+	}
+	for _, red := range bestRed {
+		for _, blue := range bestBlue {
+			generator.opponents[makePair(red, blue)]++
+		}
+	}
+	return match
+}
+
+// Repeat partners are weighted more than repeat opponents
+func (generator *generator) allianceCost(red []int, blue []int) int {
+	cost := 0
+	for _, alliance := range [][]int{red, blue} {
+		for i := 0; i < len(alliance); i++ {
+			for j := i + 1; j < len(alliance); j++ {
+				cost += generator.partners[makePair(alliance[i], alliance[j])] * 3
+			}
+		}
+	}
+	for _, redTeam := range red {
+		for _, blueTeam := range blue {
+			cost += generator.opponents[makePair(redTeam, blueTeam)]
+		}
+	}
+	return cost
+}
+
+// Scores the finished schedule, lower is better. Squaring the counts makes one pair meeting 4 times worse than two pairs meeting twice.
+func (generator *generator) score() int {
+	score := 0
+	for _, count := range generator.partners {
+		score += count * count * 3
+	}
+	for _, count := range generator.opponents {
+		score += count * count
+	}
+	return score
+}
@@ -0,0 +1,108 @@
+package schedule
+
+import (
+	"errors"
+	"fmt"
+	"github.com/McMackety/nevermore/database"
+	"github.com/jinzhu/gorm"
+)
+
+// The station names in the same order as Match.Teams
+var stationNames = [6]string{"red1", "red2", "red3", "blue1", "blue2", "blue3"}
+
+// Turns a scheduled match back into a Match
+func fromScheduledMatch(scheduledMatch database.ScheduledMatch) Match {
+	return Match{
+		Number: scheduledMatch.Number,
+		Teams: [6]int{
+			scheduledMatch.Red1, scheduledMatch.Red2, scheduledMatch.Red3,
+			scheduledMatch.Blue1, scheduledMatch.Blue2, scheduledMatch.Blue3,
+		},
+		Surrogates: [6]bool{
+			scheduledMatch.Red1Surrogate, scheduledMatch.Red2Surrogate, scheduledMatch.Red3Surrogate,
+			scheduledMatch.Blue1Surrogate, scheduledMatch.Blue2Surrogate, scheduledMatch.Blue3Surrogate,
+		},
+	}
+}
+
+// Checks a team list for bad and duplicate team numbers
+func ValidateTeams(teams []database.Team) error {
+	seen := make(map[int]bool)
+	for _, team := range teams {
+		if team.Number <= 0 {
+			return fmt.Errorf("%d isn't a valid team number", team.Number)
+		}
+		if seen[team.Number] {
+			return fmt.Errorf("team %d is in the list twice", team.Number)
+		}
+		seen[team.Number] = true
+	}
+	return nil
+}
+
+// Checks that every match has a unique number, a team in every station, no team twice and only registered teams
+func ValidateSchedule(matches []database.ScheduledMatch, registeredTeams []database.Team) error {
+	if len(matches) == 0 {
+		return errors.New("the schedule doesn't have any matches")
+	}
+	registered := make(map[int]bool)
+	for _, team := range registeredTeams {
+		registered[team.Number] = true
+	}
+	matchNumbers := make(map[int]bool)
+	for _, scheduledMatch := range matches {
+		match := fromScheduledMatch(scheduledMatch)
+		if match.Number <= 0 {
+			return fmt.Errorf("%d isn't a valid match number", match.Number)
+		}
+		if matchNumbers[match.Number] {
+			return fmt.Errorf("match %d is in the schedule twice", match.Number)
+		}
+		matchNumbers[match.Number] = true
+
+		inMatch := make(map[int]bool)
+		for station, team := range match.Teams {
+			if team == 0 {
+				return fmt.Errorf("match %d is missing a team in %s", match.Number, stationNames[station])
+			}
+			if inMatch[team] {
+				return fmt.Errorf("team %d is in match %d twice", team, match.Number)
+			}
+			inMatch[team] = true
+			if !registered[team] {
+				return fmt.Errorf("team %d in match %d isn't registered for the event", team, match.Number)
+			}
+		}
+	}
+	return nil
+}
+
+// Validates a team list and adds it to the database, teams that are already registered are updated.
+// Nothing is saved if any team is bad.
+func ImportTeams(teams []database.Team) error {
+	if err := ValidateTeams(teams); err != nil {
+		return err
+	}
+	// IDs from an export are from another database
+	for i := range teams {
+		teams[i].Model = gorm.Model{}
+	}
+	return database.SaveTeams(teams)
+}
+
+// Validates a schedule against the registered teams and replaces the schedule for the level with it.
+// Matches keep whether they were played, so a partly played schedule that was exported can be imported again.
+func ImportSchedule(level int, matches []database.ScheduledMatch) error {
+	if err := ValidateSchedule(matches, database.GetAllTeams()); err != nil {
+		return err
+	}
+	if event, err := database.GetCurrentEvent(); err == nil {
+		for i := range matches {
+			matches[i].EventID = event.ID
+		}
+	}
+	for i := range matches {
+		matches[i].Model = gorm.Model{}
+	}
+	return database.ReplaceSchedule(level, matches)
+}
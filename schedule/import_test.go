@@ -0,0 +1,66 @@
+package schedule
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/McMackety/nevermore/database"
+	"github.com/McMackety/nevermore/database/testutil"
+	"github.com/McMackety/nevermore/enums"
+)
+
+func TestImportingTeamsUpdatesRegisteredTeams(t *testing.T) {
+	testutil.UseTestDatabase(t)
+
+	if err := ImportTeams([]database.Team{{Number: 254, Name: "Cheesy Poofs"}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := ImportTeams([]database.Team{{Number: 254, Name: "The Cheesy Poofs"}, {Number: 1678}}); err != nil {
+		t.Fatal(err)
+	}
+	teams := database.GetAllTeams()
+	if len(teams) != 2 || teams[0].Number != 254 || teams[0].Name != "The Cheesy Poofs" || teams[1].Number != 1678 {
+		t.Errorf("expected team 254 to be updated and 1678 to be added, got %+v", teams)
+	}
+}
+
+func TestExportedScheduleImportsWithPlayedMatches(t *testing.T) {
+	testutil.UseTestDatabase(t)
+	var teams []database.Team
+	for teamNum := 1; teamNum <= 12; teamNum++ {
+		teams = append(teams, database.Team{Number: teamNum})
+	}
+	if err := ImportTeams(teams); err != nil {
+		t.Fatal(err)
+	}
+	level := int(enums.QUALIFICATION)
+	matches, err := GenerateForTeams(level, 2, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, match := range matches[:2] {
+		if err := database.MarkScheduledMatchPlayed(level, match.Number); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var exported bytes.Buffer
+	if err := WriteSchedule(&exported, CSV, database.GetSchedule(level)); err != nil {
+		t.Fatal(err)
+	}
+	imported, err := ReadSchedule(&exported, CSV)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ImportSchedule(level, imported); err != nil {
+		t.Fatal(err)
+	}
+	for _, match := range database.GetSchedule(level) {
+		if expected := match.Number <= 2; match.Played != expected {
+			t.Errorf("expected match %d to have played %t after it was imported again, it's %t", match.Number, expected, match.Played)
+		}
+	}
+	if next, err := database.GetNextScheduledMatch(level); err != nil || next.Number != 3 {
+		t.Errorf("expected match 3 to be next, got %d (%v)", next.Number, err)
+	}
+}
@@ -0,0 +1,51 @@
+package schedule
+
+import (
+	"github.com/McMackety/nevermore/database"
+)
+
+// Converts generated matches into scheduled matches for the database
+func ToScheduledMatches(matches []Match, level int) []database.ScheduledMatch {
+	var scheduledMatches []database.ScheduledMatch
+	for _, match := range matches {
+		scheduledMatches = append(scheduledMatches, database.ScheduledMatch{
+			Level:          level,
+			Number:         match.Number,
+			Red1:           match.Teams[0],
+			Red2:           match.Teams[1],
+			Red3:           match.Teams[2],
+			Blue1:          match.Teams[3],
+			Blue2:          match.Teams[4],
+			Blue3:          match.Teams[5],
+			Red1Surrogate:  match.Surrogates[0],
+			Red2Surrogate:  match.Surrogates[1],
+			Red3Surrogate:  match.Surrogates[2],
+			Blue1Surrogate: match.Surrogates[3],
+			Blue2Surrogate: match.Surrogates[4],
+			Blue3Surrogate: match.Surrogates[5],
+		})
+	}
+	return scheduledMatches
+}
+
+// Generates a schedule for every team in the database and saves it, replacing the old schedule for the level
+func GenerateForTeams(level int, matchesPerTeam int, minTurnaround int) ([]database.ScheduledMatch, error) {
+	var teams []int
+	for _, team := range database.GetAllTeams() {
+		teams = append(teams, team.Number)
+	}
+	matches, err := Generate(teams, matchesPerTeam, minTurnaround)
+	if err != nil {
+		return nil, err
+	}
+	scheduledMatches := ToScheduledMatches(matches, level)
+	if event, err := database.GetCurrentEvent(); err == nil {
+		for i := range scheduledMatches {
+			scheduledMatches[i].EventID = event.ID
+		}
+	}
+	if err := database.ReplaceSchedule(level, scheduledMatches); err != nil {
+		return nil, err
+	}
+	return scheduledMatches, nil
+}
@@ -0,0 +1,74 @@
+package scoring
+
+import (
+	"github.com/mitchellh/mapstructure"
+)
+
+// RankingRules are how a game awards ranking points and breaks ties, these change every year along with the ScoringInterface
+type RankingRules interface {
+	// The ranking points an alliance earned in a match
+	GetRankingPoints(score int, opponentScore int, scoringData map[string]interface{}) int
+	// The value of each tiebreaker an alliance earned in a match, in the order they are checked.
+	// Each one is averaged over the matches a team played and the higher average wins.
+	GetTiebreakers(score int, opponentScore int, scoringData map[string]interface{}) []float64
+	// The name of each tiebreaker, in the same order as GetTiebreakers
+	GetTiebreakerNames() []string
+	// The values that break a tied playoff match, in the order they are checked and the higher value wins.
+	// If every one is tied too the match is replayed.
+	GetPlayoffTiebreakers(scoringData map[string]interface{}) []int
+}
+
+func CreateRankingRules() RankingRules {
+	return &InfiniteRechargeRankingRules{}
+}
+
+// The endgame points an alliance needs for the Shield Generator Operational ranking point
+const shieldGeneratorOperationalPoints = 65
+
+// InfiniteRechargeRankingRules are the 2020 ranking rules
+type InfiniteRechargeRankingRules struct{}
+
+// 2 ranking points for a win, 1 for a tie, 1 for Shield Generator Operational and 1 for Shield Generator Energized
+func (rules *InfiniteRechargeRankingRules) GetRankingPoints(score int, opponentScore int, scoringData map[string]interface{}) int {
+	data := decodeInfiniteRechargeScoringData(scoringData)
+	rankingPoints := 0
+	if score > opponentScore {
+		rankingPoints += 2
+	} else if score == opponentScore {
+		rankingPoints++
+	}
+	if data.endgamePoints() >= shieldGeneratorOperationalPoints {
+		rankingPoints++
+	}
+	if data.PositionControlCompleted {
+		rankingPoints++
+	}
+	return rankingPoints
+}
+
+// Ties are broken by auto points, then endgame points, then teleop power cell and control panel points
+func (rules *InfiniteRechargeRankingRules) GetTiebreakers(score int, opponentScore int, scoringData map[string]interface{}) []float64 {
+	data := decodeInfiniteRechargeScoringData(scoringData)
+	return []float64{
+		float64(data.autoPoints()),
+		float64(data.endgamePoints()),
+		float64(data.teleopPoints()),
+	}
+}
+
+func (rules *InfiniteRechargeRankingRules) GetTiebreakerNames() []string {
+	return []string{"autoPoints", "endgamePoints", "teleopPoints"}
+}
+
+// Playoff ties go to the alliance with more foul points, then auto points, then endgame points, then teleop points
+func (rules *InfiniteRechargeRankingRules) GetPlayoffTiebreakers(scoringData map[string]interface{}) []int {
+	data := decodeInfiniteRechargeScoringData(scoringData)
+	return []int{data.foulPoints(), data.autoPoints(), data.endgamePoints(), data.teleopPoints()}
+}
+
+// Decodes scoring data from GetScoringDataRed/Blue, after it's been through JSON the numbers are all float64s
+func decodeInfiniteRechargeScoringData(scoringData map[string]interface{}) InfiniteRechargeScoringData {
+	var data InfiniteRechargeScoringData
+	mapstructure.Decode(scoringData, &data)
+	return data
+}
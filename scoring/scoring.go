@@ -12,8 +12,15 @@ type ScoringInterface interface {
 	GetScoringDataRed() map[string]interface{}
 	GetScoringDataBlue() map[string]interface{}
 	GetFinalScore() (redScore int, blueScore int)
+	GetFoulPoints() (redFoulPoints int, blueFoulPoints int)
 	ShouldSendColorRed(currentColor string) bool
 	ShouldSendColorBlue(currentColor string) bool
+	GetColorRed() string
+	GetColorBlue() string
+	GetFoulsRed() (fouls int, techFouls int)
+	GetFoulsBlue() (fouls int, techFouls int)
+	SetFoulsRed(fouls int, techFouls int)
+	SetFoulsBlue(fouls int, techFouls int)
 }
 
 func CreateScoringInterface() ScoringInterface {
@@ -86,6 +93,11 @@ func (scoring *InfiniteRechargeScoring) GetFinalScore() (redScore int, blueScore
 	return scoring.RedData.calcScore(), scoring.BlueData.calcScore()
 }
 
+// Points each alliance gets from the other alliance's fouls, they aren't part of the final score
+func (scoring *InfiniteRechargeScoring) GetFoulPoints() (redFoulPoints int, blueFoulPoints int) {
+	return scoring.RedData.foulPoints(), scoring.BlueData.foulPoints()
+}
+
 func (scoring *InfiniteRechargeScoring) ShouldSendColorRed(currentColor string) bool {
 	if scoring.RedColor == "" {
 		if scoring.RedData.RotationControlCompleted && scoring.RedData.TotalPowerCells >= 9 + 20 {
@@ -106,6 +118,34 @@ func (scoring *InfiniteRechargeScoring) ShouldSendColorBlue(currentColor string)
 	return false
 }
 
+func (scoring *InfiniteRechargeScoring) GetColorRed() string {
+	return scoring.RedColor
+}
+
+func (scoring *InfiniteRechargeScoring) GetColorBlue() string {
+	return scoring.BlueColor
+}
+
+// Fouls committed by the red alliance, which are stored as opponent fouls on blue's data
+func (scoring *InfiniteRechargeScoring) GetFoulsRed() (fouls int, techFouls int) {
+	return scoring.BlueData.OpponentFoul, scoring.BlueData.OpponentTechFoul
+}
+
+// Fouls committed by the blue alliance, which are stored as opponent fouls on red's data
+func (scoring *InfiniteRechargeScoring) GetFoulsBlue() (fouls int, techFouls int) {
+	return scoring.RedData.OpponentFoul, scoring.RedData.OpponentTechFoul
+}
+
+func (scoring *InfiniteRechargeScoring) SetFoulsRed(fouls int, techFouls int) {
+	scoring.BlueData.OpponentFoul = fouls
+	scoring.BlueData.OpponentTechFoul = techFouls
+}
+
+func (scoring *InfiniteRechargeScoring) SetFoulsBlue(fouls int, techFouls int) {
+	scoring.RedData.OpponentFoul = fouls
+	scoring.RedData.OpponentTechFoul = techFouls
+}
+
 type InfiniteRechargeScoringData struct {
 	AutoInitiationLine int
 	TotalPowerCells int
@@ -126,11 +166,21 @@ type InfiniteRechargeScoringData struct {
 }
 
 func (scoreData *InfiniteRechargeScoringData) calcScore() int {
+	return scoreData.autoPoints() + scoreData.teleopPoints() + scoreData.endgamePoints()
+}
+
+func (scoreData *InfiniteRechargeScoringData) autoPoints() int {
 	score := 0
 	score += scoreData.AutoInitiationLine*5
 	score += scoreData.AutoInnerPowerCells*6
 	score += scoreData.AutoOuterPowerCells*4
 	score += scoreData.AutoLowPowerCells*2
+	return score
+}
+
+// Teleop power cells and the control panel
+func (scoreData *InfiniteRechargeScoringData) teleopPoints() int {
+	score := 0
 	score += scoreData.TeleopInnerPowerCells*3
 	score += scoreData.TeleopOuterPowerCells*2
 	score += scoreData.TeleopLowPowerCells*1
@@ -140,6 +190,12 @@ func (scoreData *InfiniteRechargeScoringData) calcScore() int {
 	if scoreData.PositionControlCompleted {
 		score += 20
 	}
+	return score
+}
+
+// Hanging, parking and leveling the shield generator switch
+func (scoreData *InfiniteRechargeScoringData) endgamePoints() int {
+	score := 0
 	score += scoreData.HangingRobots*25
 	score += scoreData.ParkedRobots*5
 	if scoreData.LevelSwitch && scoreData.HangingRobots > 0 {
@@ -148,6 +204,11 @@ func (scoreData *InfiniteRechargeScoringData) calcScore() int {
 	return score
 }
 
+// Points from the other alliance's fouls
+func (scoreData *InfiniteRechargeScoringData) foulPoints() int {
+	return scoreData.OpponentFoul*3 + scoreData.OpponentTechFoul*15
+}
+
 func getRandomColor(otherThan string) string {
 	for {
 		switch rand.Intn(4) {
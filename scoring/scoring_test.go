@@ -0,0 +1,17 @@
+package scoring
+
+import "testing"
+
+func TestFoulsArentPartOfTheFinalScore(t *testing.T) {
+	scorer := CreateScoringInterface()
+	scorer.UpdateRedScoringData(map[string]interface{}{"AutoInitiationLine": 3, "HangingRobots": 1})
+	scorer.SetFoulsBlue(2, 1)
+	redScore, blueScore := scorer.GetFinalScore()
+	if redScore != 40 || blueScore != 0 {
+		t.Errorf("expected a final score of 40 to 0, got %d to %d", redScore, blueScore)
+	}
+	redFoulPoints, blueFoulPoints := scorer.GetFoulPoints()
+	if redFoulPoints != 21 || blueFoulPoints != 0 {
+		t.Errorf("expected red to get 21 points from blue's fouls, got %d and %d", redFoulPoints, blueFoulPoints)
+	}
+}
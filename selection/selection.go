@@ -0,0 +1,379 @@
+package selection
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/McMackety/nevermore/database"
+	"github.com/McMackety/nevermore/events"
+	"github.com/McMackety/nevermore/rankings"
+	"sync"
+)
+
+// The alliance selection being run, it's nil until one is started or loaded.
+// Start and the web server can both get to it, so it's guarded by currentSelectionMutex.
+var currentSelection *Selection
+var currentSelectionMutex sync.Mutex
+
+// Alliance is an alliance being picked, the first team is the captain
+type Alliance struct {
+	Number    int   `json:"number"`
+	Teams     []int `json:"teams"`
+	Backup    int   `json:"backup"`
+	BackupFor int   `json:"backupFor"` // The team the backup robot replaced
+}
+
+// Invitation is a pick waiting for the invited team to accept or decline
+type Invitation struct {
+	Alliance   int `json:"alliance"`
+	TeamNumber int `json:"teamNum"`
+}
+
+// Selection is the state of alliance selection, it's published as the allianceSelection event every time it changes
+type Selection struct {
+	mutex            sync.Mutex
+	TeamsPerAlliance int         `json:"teamsPerAlliance"`
+	Alliances        []Alliance  `json:"alliances"`
+	Rankings         []int       `json:"rankings"` // Team numbers in the order they ranked
+	Declined         []int       `json:"declined"`
+	Invitation       *Invitation `json:"invitation"`
+	Round            int         `json:"round"`           // Starts at 1, 0 once every pick has been made
+	PickingAlliance  int         `json:"pickingAlliance"` // 0 once every pick has been made
+	Backups          []int       `json:"backups"`         // The best ranked teams left over once every pick has been made
+}
+
+// Gets the alliance selection being run
+func GetCurrentSelection() (*Selection, error) {
+	currentSelectionMutex.Lock()
+	defer currentSelectionMutex.Unlock()
+	if currentSelection == nil {
+		return nil, errors.New("alliance selection hasn't started")
+	}
+	return currentSelection, nil
+}
+
+func setCurrentSelection(selection *Selection) {
+	currentSelectionMutex.Lock()
+	defer currentSelectionMutex.Unlock()
+	currentSelection = selection
+}
+
+// Starts alliance selection from the qualification rankings, the top ranked teams are the captains
+func Start(allianceCount int, teamsPerAlliance int) error {
+	if allianceCount < 2 {
+		return errors.New("there have to be at least 2 alliances")
+	}
+	if teamsPerAlliance < 2 || teamsPerAlliance > 4 {
+		return errors.New("an alliance has to have 2 to 4 teams")
+	}
+	selection := &Selection{TeamsPerAlliance: teamsPerAlliance}
+	for _, ranking := range rankings.GetQualificationRankings().Rankings {
+		selection.Rankings = append(selection.Rankings, ranking.TeamNumber)
+	}
+	if len(selection.Rankings) < allianceCount*teamsPerAlliance {
+		return fmt.Errorf("%d ranked teams isn't enough for %d alliances of %d", len(selection.Rankings), allianceCount, teamsPerAlliance)
+	}
+	for number := 1; number <= allianceCount; number++ {
+		selection.Alliances = append(selection.Alliances, Alliance{Number: number, Teams: []int{selection.Rankings[number-1]}})
+	}
+	selection.update()
+	if err := selection.save(); err != nil {
+		return err
+	}
+	setCurrentSelection(selection)
+	events.Publish("allianceSelection", selection)
+	return nil
+}
+
+// Loads the alliance selection that was saved in the database, if there is one
+func Load() error {
+	saved, err := database.GetAllianceSelection()
+	if err != nil {
+		return nil
+	}
+	selection := &Selection{
+		TeamsPerAlliance: saved.TeamsPerAlliance,
+		Declined:         database.GetDeclinedTeams(),
+		Round:            saved.Round,
+		PickingAlliance:  saved.PickingAlliance,
+	}
+	if err := json.Unmarshal([]byte(saved.Rankings), &selection.Rankings); err != nil {
+		return errors.New("couldn't decode the saved alliance selection rankings: " + err.Error())
+	}
+	if saved.InvitedTeam != 0 {
+		selection.Invitation = &Invitation{Alliance: saved.InvitedAlliance, TeamNumber: saved.InvitedTeam}
+	}
+	for _, savedAlliance := range database.GetAlliances() {
+		alliance := Alliance{Number: savedAlliance.Number, Backup: savedAlliance.Backup, BackupFor: savedAlliance.BackupFor}
+		for _, teamNum := range []int{savedAlliance.Captain, savedAlliance.Pick1, savedAlliance.Pick2, savedAlliance.Pick3} {
+			if teamNum != 0 {
+				alliance.Teams = append(alliance.Teams, teamNum)
+			}
+		}
+		selection.Alliances = append(selection.Alliances, alliance)
+	}
+	if selection.PickingAlliance == 0 {
+		selection.Backups = selection.leftoverTeams()
+	}
+	setCurrentSelection(selection)
+	return nil
+}
+
+// Gets a copy of the selection
+func (selection *Selection) Get() *Selection {
+	selection.mutex.Lock()
+	defer selection.mutex.Unlock()
+	return selection.clone()
+}
+
+// Copies the selection, the caller has to hold the mutex
+func (selection *Selection) clone() *Selection {
+	copied := &Selection{
+		TeamsPerAlliance: selection.TeamsPerAlliance,
+		Rankings:         append([]int(nil), selection.Rankings...),
+		Declined:         append([]int(nil), selection.Declined...),
+		Invitation:       selection.Invitation,
+		Round:            selection.Round,
+		PickingAlliance:  selection.PickingAlliance,
+		Backups:          append([]int(nil), selection.Backups...),
+	}
+	for _, alliance := range selection.Alliances {
+		alliance.Teams = append([]int(nil), alliance.Teams...)
+		copied.Alliances = append(copied.Alliances, alliance)
+	}
+	return copied
+}
+
+// Puts back everything a pick, response or backup can change from a clone, so a change that couldn't be saved is undone
+func (selection *Selection) restore(saved *Selection) {
+	selection.Alliances = saved.Alliances
+	selection.Declined = saved.Declined
+	selection.Invitation = saved.Invitation
+	selection.Round = saved.Round
+	selection.PickingAlliance = saved.PickingAlliance
+	selection.Backups = saved.Backups
+}
+
+// The picking alliance invites a team, which then has to accept or decline
+func (selection *Selection) Pick(teamNum int) error {
+	selection.mutex.Lock()
+	defer selection.mutex.Unlock()
+	if selection.PickingAlliance == 0 {
+		return errors.New("every pick has already been made")
+	}
+	if selection.Invitation != nil {
+		return fmt.Errorf("team %d hasn't answered their invitation yet", selection.Invitation.TeamNumber)
+	}
+	if err := selection.canBePicked(teamNum); err != nil {
+		return err
+	}
+	saved := selection.clone()
+	selection.Invitation = &Invitation{Alliance: selection.PickingAlliance, TeamNumber: teamNum}
+	if err := selection.save(); err != nil {
+		selection.restore(saved)
+		return err
+	}
+	events.Publish("allianceSelection", selection)
+	return nil
+}
+
+// The invited team accepts or declines, a team that declines can't be picked again
+func (selection *Selection) Respond(accept bool) error {
+	selection.mutex.Lock()
+	defer selection.mutex.Unlock()
+	if selection.Invitation == nil {
+		return errors.New("nobody has been invited")
+	}
+	invitation := *selection.Invitation
+	if accept {
+		// Checked again so accepting can never leave an alliance without any teams
+		if err := selection.canBePicked(invitation.TeamNumber); err != nil {
+			return err
+		}
+	}
+	saved := selection.clone()
+	selection.Invitation = nil
+	if accept {
+		selection.removeCaptain(invitation.TeamNumber)
+		alliance := &selection.Alliances[invitation.Alliance-1]
+		alliance.Teams = append(alliance.Teams, invitation.TeamNumber)
+	} else {
+		selection.Declined = append(selection.Declined, invitation.TeamNumber)
+	}
+	selection.update()
+	if err := selection.save(); err != nil {
+		selection.restore(saved)
+		return err
+	}
+	events.Publish("allianceSelection", selection)
+	return nil
+}
+
+// Brings a backup robot into an alliance in place of one of it's teams, it has to be one of the backups.
+// A teamNum of 0 removes the alliance's backup.
+func (selection *Selection) SetBackup(allianceNum int, teamNum int, replacedTeam int) error {
+	selection.mutex.Lock()
+	defer selection.mutex.Unlock()
+	if allianceNum < 1 || allianceNum > len(selection.Alliances) {
+		return errors.New("that alliance doesn't exist")
+	}
+	if teamNum != 0 && !contains(selection.Backups, teamNum) {
+		return fmt.Errorf("team %d isn't a backup robot", teamNum)
+	}
+	for _, alliance := range selection.Alliances {
+		if teamNum != 0 && alliance.Backup == teamNum {
+			return fmt.Errorf("team %d is already alliance %d's backup", teamNum, alliance.Number)
+		}
+	}
+	alliance := &selection.Alliances[allianceNum-1]
+	if teamNum == 0 {
+		replacedTeam = 0
+	} else if !contains(alliance.Teams, replacedTeam) {
+		return fmt.Errorf("team %d isn't on alliance %d", replacedTeam, allianceNum)
+	}
+	saved := selection.clone()
+	alliance.Backup = teamNum
+	alliance.BackupFor = replacedTeam
+	if err := selection.save(); err != nil {
+		selection.restore(saved)
+		return err
+	}
+	events.Publish("allianceSelection", selection)
+	return nil
+}
+
+// Checks that a team is allowed to join the picking alliance
+func (selection *Selection) canBePicked(teamNum int) error {
+	if !contains(selection.Rankings, teamNum) {
+		return fmt.Errorf("team %d isn't ranked", teamNum)
+	}
+	if contains(selection.Declined, teamNum) {
+		return fmt.Errorf("team %d already declined an invitation", teamNum)
+	}
+	for _, alliance := range selection.Alliances {
+		if !contains(alliance.Teams, teamNum) {
+			continue
+		}
+		// Only the captain of a lower alliance that hasn't picked anyone can be picked, as long as there's a team left to replace them
+		if alliance.Number > selection.PickingAlliance && len(alliance.Teams) == 1 {
+			if selection.replacementCaptain(teamNum) == 0 {
+				return fmt.Errorf("team %d can't leave alliance %d, there's no team left to be the last captain", teamNum, alliance.Number)
+			}
+			return nil
+		}
+		return fmt.Errorf("team %d is already on alliance %d", teamNum, alliance.Number)
+	}
+	return nil
+}
+
+// When a captain joins another alliance every alliance below it moves up and the best ranked team left becomes the last captain
+func (selection *Selection) removeCaptain(teamNum int) {
+	for index, alliance := range selection.Alliances {
+		if len(alliance.Teams) != 1 || alliance.Teams[0] != teamNum {
+			continue
+		}
+		for next := index; next < len(selection.Alliances)-1; next++ {
+			selection.Alliances[next].Teams = selection.Alliances[next+1].Teams
+		}
+		selection.Alliances[len(selection.Alliances)-1].Teams = []int{selection.replacementCaptain(teamNum)}
+		return
+	}
+}
+
+// The best ranked team that isn't on an alliance, who becomes the last captain when teamNum leaves it's alliance.
+// It's 0 when every ranked team is on an alliance.
+func (selection *Selection) replacementCaptain(teamNum int) int {
+	for _, rankedTeam := range selection.Rankings {
+		if rankedTeam != teamNum && !selection.isOnAlliance(rankedTeam) {
+			return rankedTeam
+		}
+	}
+	return 0
+}
+
+// Works out which alliance picks next, picks go 1 to N in the first round then N to 1 in the next and so on.
+// Once every pick has been made the best ranked teams left over are the backups.
+func (selection *Selection) update() {
+	picks := 0
+	for _, alliance := range selection.Alliances {
+		if len(alliance.Teams) > 1 {
+			picks += len(alliance.Teams) - 1
+		}
+	}
+	allianceCount := len(selection.Alliances)
+	selection.Backups = nil
+	if picks >= allianceCount*(selection.TeamsPerAlliance-1) {
+		selection.Round = 0
+		selection.PickingAlliance = 0
+		selection.Backups = selection.leftoverTeams()
+		return
+	}
+	round, index := picks/allianceCount, picks%allianceCount
+	if round%2 == 1 {
+		index = allianceCount - 1 - index
+	}
+	selection.Round = round + 1
+	selection.PickingAlliance = index + 1
+}
+
+// The best ranked teams that weren't picked or declined, one for each alliance
+func (selection *Selection) leftoverTeams() []int {
+	var teams []int
+	for _, teamNum := range selection.Rankings {
+		if len(teams) < len(selection.Alliances) && !selection.isOnAlliance(teamNum) && !contains(selection.Declined, teamNum) {
+			teams = append(teams, teamNum)
+		}
+	}
+	return teams
+}
+
+func (selection *Selection) isOnAlliance(teamNum int) bool {
+	for _, alliance := range selection.Alliances {
+		if contains(alliance.Teams, teamNum) {
+			return true
+		}
+	}
+	return false
+}
+
+// Saves the alliances, declined teams and how far selection has got to the database
+func (selection *Selection) save() error {
+	rankingsJSON, err := json.Marshal(selection.Rankings)
+	if err != nil {
+		return err
+	}
+	saved := database.AllianceSelection{
+		TeamsPerAlliance: selection.TeamsPerAlliance,
+		Rankings:         string(rankingsJSON),
+		Round:            selection.Round,
+		PickingAlliance:  selection.PickingAlliance,
+	}
+	if selection.Invitation != nil {
+		saved.InvitedAlliance = selection.Invitation.Alliance
+		saved.InvitedTeam = selection.Invitation.TeamNumber
+	}
+	var alliances []database.Alliance
+	for _, alliance := range selection.Alliances {
+		teams := make([]int, 4)
+		copy(teams, alliance.Teams)
+		alliances = append(alliances, database.Alliance{
+			Number:    alliance.Number,
+			Captain:   teams[0],
+			Pick1:     teams[1],
+			Pick2:     teams[2],
+			Pick3:     teams[3],
+			Backup:    alliance.Backup,
+			BackupFor: alliance.BackupFor,
+		})
+	}
+	return database.SaveAllianceSelection(saved, alliances, selection.Declined)
+}
+
+func contains(teams []int, teamNum int) bool {
+	for _, team := range teams {
+		if team == teamNum {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,209 @@
+package selection
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/McMackety/nevermore/database"
+	"github.com/McMackety/nevermore/database/testutil"
+)
+
+// Starts a selection of 4 alliances from 20 teams ranked in order of team number, without going through the rankings
+func startTestSelection(t *testing.T, teamsPerAlliance int) *Selection {
+	t.Helper()
+	return startSmallTestSelection(t, teamsPerAlliance, 20)
+}
+
+func startSmallTestSelection(t *testing.T, teamsPerAlliance int, rankedTeams int) *Selection {
+	t.Helper()
+	testutil.UseTestDatabase(t)
+
+	selection := &Selection{TeamsPerAlliance: teamsPerAlliance}
+	for teamNum := 1; teamNum <= rankedTeams; teamNum++ {
+		selection.Rankings = append(selection.Rankings, teamNum)
+	}
+	for number := 1; number <= 4; number++ {
+		selection.Alliances = append(selection.Alliances, Alliance{Number: number, Teams: []int{number}})
+	}
+	selection.update()
+	if err := selection.save(); err != nil {
+		t.Fatal(err)
+	}
+	setCurrentSelection(selection)
+	return selection
+}
+
+func TestPicking(t *testing.T) {
+	selection := startTestSelection(t, 3)
+	if err := selection.Pick(3); err != nil {
+		t.Fatal(err)
+	}
+	if err := selection.Respond(true); err != nil {
+		t.Fatal(err)
+	}
+	if teams := selection.Alliances[2].Teams; len(teams) != 1 || teams[0] != 4 {
+		t.Errorf("expected alliance 3's captain to move up once it's captain was picked, got %v", teams)
+	}
+	if selection.Pick(1) == nil {
+		t.Error("expected alliance 2 not to be able to pick a higher alliance's captain")
+	}
+	if err := selection.Pick(10); err != nil {
+		t.Fatal(err)
+	}
+	if err := selection.Respond(false); err != nil {
+		t.Fatal(err)
+	}
+	if selection.Pick(10) == nil {
+		t.Error("expected a team that declined not to be picked again")
+	}
+
+	// Picks go 1 to 4 in the first round then 4 to 1
+	expectedOrder := []int{2, 3, 4, 4, 3, 2, 1}
+	for _, expected := range expectedOrder {
+		if selection.PickingAlliance != expected {
+			t.Fatalf("expected alliance %d to be picking, it's alliance %d", expected, selection.PickingAlliance)
+		}
+		for _, teamNum := range selection.Rankings {
+			if selection.canBePicked(teamNum) == nil && !selection.isOnAlliance(teamNum) {
+				if err := selection.Pick(teamNum); err != nil {
+					t.Fatal(err)
+				}
+				if err := selection.Respond(true); err != nil {
+					t.Fatal(err)
+				}
+				break
+			}
+		}
+	}
+	if selection.PickingAlliance != 0 || selection.Round != 0 || len(selection.Backups) != 4 {
+		t.Errorf("expected every pick to be made with 4 backups, got alliance %d picking in round %d with backups %v",
+			selection.PickingAlliance, selection.Round, selection.Backups)
+	}
+}
+
+func TestCaptainCantLeaveWithoutAReplacement(t *testing.T) {
+	selection := startSmallTestSelection(t, 2, 5)
+	if err := selection.Pick(2); err != nil {
+		t.Fatal(err)
+	}
+	if err := selection.Respond(true); err != nil {
+		t.Fatal(err)
+	}
+	// Every ranked team is on an alliance now, so nobody could replace alliance 3's captain
+	if selection.Pick(4) == nil {
+		t.Error("expected a captain with nobody left to replace them not to be picked")
+	}
+	selection.Invitation = &Invitation{Alliance: 2, TeamNumber: 4}
+	if selection.Respond(true) == nil {
+		t.Error("expected a captain with nobody left to replace them not to be able to accept")
+	}
+	for _, alliance := range selection.Alliances {
+		if len(alliance.Teams) == 0 {
+			t.Errorf("expected every alliance to keep a captain, alliance %d has no teams", alliance.Number)
+		}
+	}
+}
+
+func TestLoadKeepsProgress(t *testing.T) {
+	selection := startTestSelection(t, 2)
+	for _, teamNum := range []int{11, 12, 13, 14} {
+		if err := selection.Pick(teamNum); err != nil {
+			t.Fatal(err)
+		}
+		if err := selection.Respond(true); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if selection.SetBackup(2, 5, 11) == nil {
+		t.Error("expected a backup not to replace a team on another alliance")
+	}
+	if err := selection.SetBackup(2, 5, 2); err != nil {
+		t.Fatal(err)
+	}
+	setCurrentSelection(nil)
+	if err := Load(); err != nil {
+		t.Fatal(err)
+	}
+	if loaded, _ := GetCurrentSelection(); loaded.Alliances[1].Backup != 5 || loaded.Alliances[1].BackupFor != 2 {
+		t.Errorf("expected team 5 to replace team 2 on alliance 2, got %+v", loaded.Alliances[1])
+	}
+
+	selection = startTestSelection(t, 4)
+	if err := selection.Pick(11); err != nil {
+		t.Fatal(err)
+	}
+	setCurrentSelection(nil)
+	if err := Load(); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := GetCurrentSelection()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if loaded.TeamsPerAlliance != 4 || loaded.Round != 1 || loaded.PickingAlliance != 1 {
+		t.Errorf("expected 4 teams per alliance with alliance 1 picking in round 1, got %d with alliance %d picking in round %d",
+			loaded.TeamsPerAlliance, loaded.PickingAlliance, loaded.Round)
+	}
+	if loaded.Invitation == nil || loaded.Invitation.Alliance != 1 || loaded.Invitation.TeamNumber != 11 {
+		t.Errorf("expected team 11's invitation to alliance 1 to be waiting, got %+v", loaded.Invitation)
+	}
+	if err := loaded.Respond(true); err != nil {
+		t.Fatal(err)
+	}
+	if loaded.PickingAlliance != 2 {
+		t.Errorf("expected alliance 2 to pick after the invitation was accepted, it's alliance %d", loaded.PickingAlliance)
+	}
+}
+
+func TestChangesThatCantBeSavedAreUndone(t *testing.T) {
+	selection := startTestSelection(t, 3)
+	if err := selection.Pick(2); err != nil {
+		t.Fatal(err)
+	}
+	before := selection.Get()
+	database.Database.Close()
+	if selection.Respond(true) == nil {
+		t.Fatal("expected accepting to fail when it can't be saved")
+	}
+	if after := selection.Get(); !reflect.DeepEqual(after, before) {
+		t.Errorf("expected the selection not to change when it couldn't be saved, it went from %+v to %+v", before, after)
+	}
+
+	selection = startTestSelection(t, 2)
+	for _, teamNum := range []int{11, 12, 13, 14} {
+		if err := selection.Pick(teamNum); err != nil {
+			t.Fatal(err)
+		}
+		if err := selection.Respond(true); err != nil {
+			t.Fatal(err)
+		}
+	}
+	before = selection.Get()
+	database.Database.Close()
+	if selection.SetBackup(1, 5, 1) == nil {
+		t.Fatal("expected the backup to fail when it can't be saved")
+	}
+	if after := selection.Get(); !reflect.DeepEqual(after, before) {
+		t.Errorf("expected the selection not to change when it couldn't be saved, it went from %+v to %+v", before, after)
+	}
+}
+
+func TestCurrentSelectionIsSafeToShare(t *testing.T) {
+	startTestSelection(t, 3)
+	var waitGroup sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		waitGroup.Add(2)
+		go func() {
+			defer waitGroup.Done()
+			setCurrentSelection(&Selection{TeamsPerAlliance: 3})
+		}()
+		go func() {
+			defer waitGroup.Done()
+			if selection, err := GetCurrentSelection(); err == nil {
+				selection.Get()
+			}
+		}()
+	}
+	waitGroup.Wait()
+}
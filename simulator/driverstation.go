@@ -0,0 +1,331 @@
+package simulator
+
+import (
+	"errors"
+	"io"
+	"log"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DriverStation is a fake FRC driverstation that talks to the FMS like a real one would
+type DriverStation struct {
+	TeamNumber     int
+	LocalIP        string
+	StatusInterval time.Duration
+
+	mutex             sync.Mutex
+	batteryVoltage    float64
+	emergencyStop     bool
+	autonomousStop    bool
+	radioPing         bool
+	rioPing           bool
+	comms             bool
+	sequenceNum       int
+	lastControlPacket ControlPacket
+	station           int
+	stationStatus     int
+	eventName         string
+	gameSpecificData  string
+	tcpConn           net.Conn
+	udpListener       *net.UDPConn
+	udpConn           net.Conn
+	closed            chan bool
+}
+
+// Creates a driverstation that is connected, has a healthy battery and isn't e-stopped
+func CreateDriverStation(teamNum int, localIP string) *DriverStation {
+	return &DriverStation{
+		TeamNumber:     teamNum,
+		LocalIP:        localIP,
+		StatusInterval: time.Millisecond * 20,
+		batteryVoltage: 12.5,
+		radioPing:      true,
+		rioPing:        true,
+		comms:          true,
+		closed:         make(chan bool),
+	}
+}
+
+// Connects to the FMS, fmsIP is the FMS's address and the ports match config.NetworkConfig
+func (driverStation *DriverStation) Connect(fmsIP string, tcpPort int, udpPort int, driverStationUDPPort int) error {
+	if driverStation.StatusInterval <= 0 {
+		return errors.New("the status interval has to be more than 0")
+	}
+	localAddr, err := net.ResolveUDPAddr("udp4", net.JoinHostPort(driverStation.LocalIP, strconv.Itoa(driverStationUDPPort)))
+	if err != nil {
+		return err
+	}
+	udpListener, err := net.ListenUDP("udp4", localAddr)
+	if err != nil {
+		return err
+	}
+
+	udpConn, err := net.Dial("udp4", net.JoinHostPort(fmsIP, strconv.Itoa(udpPort)))
+	if err != nil {
+		udpListener.Close()
+		return err
+	}
+
+	dialer := net.Dialer{LocalAddr: &net.TCPAddr{IP: net.ParseIP(driverStation.LocalIP)}}
+	tcpConn, err := dialer.Dial("tcp", net.JoinHostPort(fmsIP, strconv.Itoa(tcpPort)))
+	if err != nil {
+		udpListener.Close()
+		udpConn.Close()
+		return err
+	}
+
+	driverStation.tcpConn = tcpConn
+	driverStation.udpListener = udpListener
+	driverStation.udpConn = udpConn
+
+	// The first thing a driverstation does is tell the FMS who it is
+	if _, err := tcpConn.Write(frame(0x18, []byte{byte(driverStation.TeamNumber >> 8 & 0xff), byte(driverStation.TeamNumber & 0xff)})); err != nil {
+		driverStation.Close()
+		return err
+	}
+
+	go driverStation.sendStatusLoop()
+	go driverStation.receiveUDPLoop()
+	go driverStation.receiveTCPLoop()
+	return nil
+}
+
+// Disconnects from the FMS
+func (driverStation *DriverStation) Close() {
+	driverStation.mutex.Lock()
+	defer driverStation.mutex.Unlock()
+	select {
+	case <-driverStation.closed:
+		return
+	default:
+	}
+	close(driverStation.closed)
+	if driverStation.tcpConn != nil {
+		driverStation.tcpConn.Close()
+	}
+	if driverStation.udpListener != nil {
+		driverStation.udpListener.Close()
+	}
+	if driverStation.udpConn != nil {
+		driverStation.udpConn.Close()
+	}
+}
+
+// Sets the battery voltage reported to the FMS
+func (driverStation *DriverStation) SetBatteryVoltage(voltage float64) {
+	driverStation.mutex.Lock()
+	defer driverStation.mutex.Unlock()
+	driverStation.batteryVoltage = voltage
+}
+
+// Presses (or releases) the e-stop button
+func (driverStation *DriverStation) SetEmergencyStop(emergencyStop bool) {
+	driverStation.mutex.Lock()
+	defer driverStation.mutex.Unlock()
+	driverStation.emergencyStop = emergencyStop
+}
+
+// Presses (or releases) the a-stop button
+func (driverStation *DriverStation) SetAutonomousStop(autonomousStop bool) {
+	driverStation.mutex.Lock()
+	defer driverStation.mutex.Unlock()
+	driverStation.autonomousStop = autonomousStop
+}
+
+// Sets the connection flags reported to the FMS
+func (driverStation *DriverStation) SetPings(comms bool, radioPing bool, rioPing bool) {
+	driverStation.mutex.Lock()
+	defer driverStation.mutex.Unlock()
+	driverStation.comms = comms
+	driverStation.radioPing = radioPing
+	driverStation.rioPing = rioPing
+}
+
+// Gets the last control packet the FMS sent
+func (driverStation *DriverStation) LastControlPacket() ControlPacket {
+	driverStation.mutex.Lock()
+	defer driverStation.mutex.Unlock()
+	return driverStation.lastControlPacket
+}
+
+// Gets the station and status the FMS last assigned over TCP
+func (driverStation *DriverStation) StationInfo() (station int, status int) {
+	driverStation.mutex.Lock()
+	defer driverStation.mutex.Unlock()
+	return driverStation.station, driverStation.stationStatus
+}
+
+// Gets the event name the FMS sent
+func (driverStation *DriverStation) EventName() string {
+	driverStation.mutex.Lock()
+	defer driverStation.mutex.Unlock()
+	return driverStation.eventName
+}
+
+// Gets the game specific data the FMS sent
+func (driverStation *DriverStation) GameSpecificData() string {
+	driverStation.mutex.Lock()
+	defer driverStation.mutex.Unlock()
+	return driverStation.gameSpecificData
+}
+
+// Sends a status packet to the FMS every StatusInterval and a TCP keep alive every second
+func (driverStation *DriverStation) sendStatusLoop() {
+	statusTicker := time.NewTicker(driverStation.StatusInterval)
+	keepAliveTicker := time.NewTicker(time.Second)
+	defer statusTicker.Stop()
+	defer keepAliveTicker.Stop()
+	for {
+		select {
+		case <-driverStation.closed:
+			return
+		case <-statusTicker.C:
+			driverStation.udpConn.Write(driverStation.statusPacket())
+		case <-keepAliveTicker.C:
+			driverStation.tcpConn.Write(frame(0x1d, nil))
+		}
+	}
+}
+
+// Builds the UDP status packet that the FMS reads in handleUDPMessage
+func (driverStation *DriverStation) statusPacket() []byte {
+	driverStation.mutex.Lock()
+	defer driverStation.mutex.Unlock()
+
+	var packet [8]byte
+	packet[0] = byte(driverStation.sequenceNum >> 8 & 0xff)
+	packet[1] = byte(driverStation.sequenceNum & 0xff)
+	packet[2] = 0 // Comm Version
+
+	packet[3] = 0
+	if driverStation.emergencyStop {
+		packet[3] |= 0x80
+	}
+	if driverStation.autonomousStop {
+		packet[3] |= 0x40
+	}
+	if driverStation.comms {
+		packet[3] |= 0x20
+	}
+	if driverStation.radioPing {
+		packet[3] |= 0x10
+	}
+	if driverStation.rioPing {
+		packet[3] |= 0x08
+	}
+	// The robot follows whatever the FMS tells it to do
+	if driverStation.lastControlPacket.Enabled && !driverStation.emergencyStop {
+		packet[3] |= 0x04
+	}
+	if driverStation.lastControlPacket.Autonomous {
+		packet[3] |= 0x02 // Autonomous mode
+	}
+
+	packet[4] = byte(driverStation.TeamNumber >> 8 & 0xff)
+	packet[5] = byte(driverStation.TeamNumber & 0xff)
+
+	wholeVolts := int(driverStation.batteryVoltage)
+	packet[6] = byte(wholeVolts)
+	packet[7] = byte((driverStation.batteryVoltage - float64(wholeVolts)) * 256)
+
+	driverStation.sequenceNum++
+	return packet[:]
+}
+
+// Reads control packets from the FMS
+func (driverStation *DriverStation) receiveUDPLoop() {
+	var bytes [100]byte
+	for {
+		length, err := driverStation.udpListener.Read(bytes[:])
+		if err != nil {
+			return
+		}
+		packet, err := ParseControlPacket(bytes[:length])
+		if err != nil {
+			log.Printf("Simulated driverstation %d got a bad control packet: %s", driverStation.TeamNumber, err.Error())
+			continue
+		}
+		driverStation.mutex.Lock()
+		driverStation.lastControlPacket = packet
+		driverStation.mutex.Unlock()
+	}
+}
+
+// Reads TCP frames from the FMS
+func (driverStation *DriverStation) receiveTCPLoop() {
+	for {
+		var size [2]byte
+		if _, err := io.ReadFull(driverStation.tcpConn, size[:]); err != nil {
+			driverStation.Close()
+			return
+		}
+		data := make([]byte, (int(size[0])<<8)+int(size[1]))
+		if _, err := io.ReadFull(driverStation.tcpConn, data); err != nil {
+			driverStation.Close()
+			return
+		}
+		if len(data) == 0 {
+			continue
+		}
+		driverStation.mutex.Lock()
+		switch data[0] {
+		case 0x14: // Event Name
+			if len(data) >= 2 && len(data) >= int(data[1])+2 {
+				driverStation.eventName = string(data[2 : int(data[1])+2])
+			}
+		case 0x19: // Station Info
+			if len(data) >= 3 {
+				driverStation.station = int(data[1])
+				driverStation.stationStatus = int(data[2])
+			}
+		case 0x1c: // Game Specific Data
+			driverStation.gameSpecificData = string(data[1:])
+		}
+		driverStation.mutex.Unlock()
+	}
+}
+
+// ControlPacket is the packet the FMS sends to every driverstation to control the robot
+type ControlPacket struct {
+	SequenceNum       int
+	Autonomous        bool
+	Enabled           bool
+	EmergencyStopped  bool
+	AutonomousStopped bool
+	Station           int
+	Level             int
+	MatchNumber       int
+	Replay            int
+	Time              time.Time
+	TimeLeft          int
+}
+
+// Parses a control packet built by the FMS
+func ParseControlPacket(packet []byte) (ControlPacket, error) {
+	if len(packet) < 22 {
+		return ControlPacket{}, errors.New("the control packet is too short")
+	}
+	return ControlPacket{
+		SequenceNum:       (int(packet[0]) << 8) + int(packet[1]),
+		Autonomous:        packet[3]&0x02 != 0,
+		Enabled:           packet[3]&0x04 != 0,
+		EmergencyStopped:  packet[3]&0x80 != 0,
+		AutonomousStopped: packet[3]&0x40 != 0,
+		Station:           int(packet[5]),
+		Level:             int(packet[6]),
+		MatchNumber:       (int(packet[7]) << 8) + int(packet[8]),
+		Replay:            int(packet[9]),
+		Time: time.Date(int(packet[19])+1900, time.Month(packet[18]), int(packet[17]), int(packet[16]), int(packet[15]), int(packet[14]),
+			((int(packet[10])<<24)+(int(packet[11])<<16)+(int(packet[12])<<8)+int(packet[13]))*1000, time.Local),
+		TimeLeft: (int(packet[20]) << 8) + int(packet[21]),
+	}, nil
+}
+
+// Builds a size prefixed TCP frame
+func frame(tag byte, payload []byte) []byte {
+	data := append([]byte{tag}, payload...)
+	return append([]byte{byte(len(data) >> 8 & 0xff), byte(len(data) & 0xff)}, data...)
+}
@@ -0,0 +1,121 @@
+package simulator
+
+import (
+	"io"
+	"io/ioutil"
+	"net"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/McMackety/nevermore/field"
+)
+
+func TestConnectRejectsInterval(t *testing.T) {
+	for _, interval := range []time.Duration{0, -time.Millisecond} {
+		driverStation := CreateDriverStation(1, "127.0.0.11")
+		driverStation.StatusInterval = interval
+		if err := driverStation.Connect("127.0.0.1", 21750, 21160, 21121); err == nil {
+			driverStation.Close()
+			t.Fatalf("expected an interval of %s to be rejected", interval)
+		}
+	}
+}
+
+// Waits for cond to be true, failing the test after timeout
+func waitFor(t *testing.T, timeout time.Duration, desc string, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(timeout)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", desc)
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+}
+
+// Gets a UDP port nothing is listening on
+func freeUDPPort(t *testing.T) int {
+	t.Helper()
+	conn, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.ParseIP("127.0.0.1")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	return conn.LocalAddr().(*net.UDPAddr).Port
+}
+
+// Runs a driverstation against a loopback FMS that places it in BLUE2 and enables it
+func TestConnectsToTheField(t *testing.T) {
+	tcpListener, err := net.Listen("tcp4", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tcpListener.Close()
+	udpListener, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.ParseIP("127.0.0.1")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer udpListener.Close()
+	driverStationUDPPort := freeUDPPort(t)
+
+	teamNums := make(chan int, 1)
+	go func() {
+		conn, err := tcpListener.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		var teamFrame [5]byte
+		if _, err := io.ReadFull(conn, teamFrame[:]); err != nil || teamFrame[2] != 0x18 {
+			return
+		}
+		teamNums <- (int(teamFrame[3]) << 8) + int(teamFrame[4])
+		conn.Write(frame(0x19, []byte{byte(field.BLUE2), byte(field.GOOD)}))
+		io.Copy(ioutil.Discard, conn)
+	}()
+
+	driverStation := CreateDriverStation(254, "127.0.0.1")
+	if err := driverStation.Connect("127.0.0.1", tcpListener.Addr().(*net.TCPAddr).Port, udpListener.LocalAddr().(*net.UDPAddr).Port, driverStationUDPPort); err != nil {
+		t.Fatal(err)
+	}
+	defer driverStation.Close()
+
+	select {
+	case teamNum := <-teamNums:
+		if teamNum != 254 {
+			t.Errorf("expected the driverstation to say it's team 254, it said %d", teamNum)
+		}
+	case <-time.After(time.Second * 2):
+		t.Fatal("timed out waiting for the driverstation to say who it is")
+	}
+	waitFor(t, time.Second*2, "the driverstation to be placed in BLUE2 with a GOOD status", func() bool {
+		station, status := driverStation.StationInfo()
+		return station == int(field.BLUE2) && status == int(field.GOOD)
+	})
+
+	var status [8]byte
+	udpListener.SetReadDeadline(time.Now().Add(time.Second * 2))
+	if _, err := udpListener.Read(status[:]); err != nil {
+		t.Fatal(err)
+	}
+	if teamNum := (int(status[4]) << 8) + int(status[5]); teamNum != 254 {
+		t.Errorf("expected the status packet to be from team 254, got %d", teamNum)
+	}
+
+	controlConn, err := net.Dial("udp4", net.JoinHostPort("127.0.0.1", strconv.Itoa(driverStationUDPPort)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer controlConn.Close()
+	var control [22]byte
+	control[3] = 0x04
+	control[5] = byte(field.BLUE2)
+	if _, err := controlConn.Write(control[:]); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, time.Second*2, "the driverstation to be enabled", func() bool {
+		packet := driverStation.LastControlPacket()
+		return packet.Enabled && packet.Station == int(field.BLUE2)
+	})
+}
@@ -0,0 +1,59 @@
+package simulator
+
+import (
+	"fmt"
+	"log"
+	"net"
+)
+
+// Simulator runs a group of fake driverstations against an FMS
+type Simulator struct {
+	DriverStations []*DriverStation
+}
+
+// Creates a simulator with a driverstation for every team.
+// Each driverstation gets its own loopback IP counting up from baseIP, so they can all listen on the driverstation UDP port.
+func CreateSimulator(teams []int, baseIP string) (*Simulator, error) {
+	ip := net.ParseIP(baseIP).To4()
+	if ip == nil {
+		return nil, fmt.Errorf("%s isn't a valid IPv4 address", baseIP)
+	}
+	simulator := &Simulator{}
+	for i, teamNum := range teams {
+		if int(ip[3])+i > 254 {
+			return nil, fmt.Errorf("there aren't enough addresses after %s for %d driverstations", baseIP, len(teams))
+		}
+		localIP := net.IPv4(ip[0], ip[1], ip[2], ip[3]+byte(i))
+		simulator.DriverStations = append(simulator.DriverStations, CreateDriverStation(teamNum, localIP.String()))
+	}
+	return simulator, nil
+}
+
+// Connects every driverstation to the FMS
+func (simulator *Simulator) Connect(fmsIP string, tcpPort int, udpPort int, driverStationUDPPort int) error {
+	for _, driverStation := range simulator.DriverStations {
+		if err := driverStation.Connect(fmsIP, tcpPort, udpPort, driverStationUDPPort); err != nil {
+			simulator.Close()
+			return fmt.Errorf("couldn't connect the driverstation for team %d: %s", driverStation.TeamNumber, err.Error())
+		}
+		log.Printf("Simulated driverstation for team %d connected from %s", driverStation.TeamNumber, driverStation.LocalIP)
+	}
+	return nil
+}
+
+// Disconnects every driverstation
+func (simulator *Simulator) Close() {
+	for _, driverStation := range simulator.DriverStations {
+		driverStation.Close()
+	}
+}
+
+// Get a simulated driverstation by it's team number
+func (simulator *Simulator) GetDriverStationByTeamNum(teamNum int) *DriverStation {
+	for _, driverStation := range simulator.DriverStations {
+		if driverStation.TeamNumber == teamNum {
+			return driverStation
+		}
+	}
+	return nil
+}
@@ -0,0 +1,386 @@
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/McMackety/nevermore/bracket"
+	"github.com/McMackety/nevermore/database"
+	"github.com/McMackety/nevermore/field"
+	"github.com/McMackety/nevermore/rankings"
+	"github.com/McMackety/nevermore/schedule"
+	"github.com/McMackety/nevermore/selection"
+	"io/ioutil"
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// apiError is the body sent back with every failed request
+type apiError struct {
+	Error string `json:"error"`
+}
+
+// authenticatedHandler is a handler that needs a logged in user
+type authenticatedHandler func(writer http.ResponseWriter, request *http.Request, userSession session)
+
+// Adds every API route to the mux
+func registerAPI(mux *http.ServeMux) {
+	mux.HandleFunc("/api/login", requireMethod(http.MethodPost, loginHandler))
+	mux.HandleFunc("/api/logout", requireMethod(http.MethodPost, logoutHandler))
+	mux.HandleFunc("/api/field", requireMethod(http.MethodGet, requireSession(getField)))
+	mux.HandleFunc("/api/match/setup", requireMethod(http.MethodPost, requireSession(commandHandler("setupMatch"))))
+	mux.HandleFunc("/api/match/next", requireMethod(http.MethodPost, requireSession(commandHandler("setupNextMatch"))))
+	mux.HandleFunc("/api/match/start", requireMethod(http.MethodPost, requireSession(commandHandler("startMatch"))))
+	mux.HandleFunc("/api/match/stop", requireMethod(http.MethodPost, requireSession(commandHandler("stopMatch"))))
+	mux.HandleFunc("/api/match/abort", requireMethod(http.MethodPost, requireSession(commandHandler("abortMatch"))))
+	mux.HandleFunc("/api/match/pause", requireMethod(http.MethodPost, requireSession(commandHandler("pauseMatch"))))
+	mux.HandleFunc("/api/match/resume", requireMethod(http.MethodPost, requireSession(commandHandler("resumeMatch"))))
+	mux.HandleFunc("/api/review", requireMethod(http.MethodGet, requireSession(requireUserType(headReferees, getReview))))
+	mux.HandleFunc("/api/review/fouls", requireMethod(http.MethodPost, requireSession(commandHandler("setFouls"))))
+	mux.HandleFunc("/api/review/cards", requireMethod(http.MethodPost, requireSession(commandHandler("setCard"))))
+	mux.HandleFunc("/api/review/commit", requireMethod(http.MethodPost, requireSession(commandHandler("commitReview"))))
+	mux.HandleFunc("/api/robots/enable", requireMethod(http.MethodPost, requireSession(commandHandler("enableAll"))))
+	mux.HandleFunc("/api/robots/disable", requireMethod(http.MethodPost, requireSession(commandHandler("disableAll"))))
+	mux.HandleFunc("/api/test/start", requireMethod(http.MethodPost, requireSession(commandHandler("startTest"))))
+	mux.HandleFunc("/api/test/stop", requireMethod(http.MethodPost, requireSession(commandHandler("stopTest"))))
+	mux.HandleFunc("/api/stations/estop", requireMethod(http.MethodPost, requireSession(commandHandler("emergencyStop"))))
+	mux.HandleFunc("/api/stations/astop", requireMethod(http.MethodPost, requireSession(commandHandler("autonomousStop"))))
+	mux.HandleFunc("/api/stations/bypass", requireMethod(http.MethodPost, requireSession(commandHandler("setBypassed"))))
+	mux.HandleFunc("/api/stations/disable", requireMethod(http.MethodPost, requireSession(commandHandler("setDisabled"))))
+	mux.HandleFunc("/api/teams", requireSession(teams))
+	mux.HandleFunc("/api/event", requireSession(currentEvent))
+	mux.HandleFunc("/api/event/select", requireMethod(http.MethodPost, requireSession(commandHandler("selectEvent"))))
+	mux.HandleFunc("/api/events", requireMethod(http.MethodGet, requireSession(getEvents)))
+	mux.HandleFunc("/api/event/teams", requireSession(eventTeams))
+	mux.HandleFunc("/api/event/teams/import", requireMethod(http.MethodPost, requireSession(requireUserType(fieldControllers, importTeams))))
+	mux.HandleFunc("/api/event/teams/export", requireMethod(http.MethodGet, requireSession(exportTeams)))
+	mux.HandleFunc("/api/schedule", requireMethod(http.MethodGet, requireSession(getSchedule)))
+	mux.HandleFunc("/api/schedule/generate", requireMethod(http.MethodPost, requireSession(commandHandler("generateSchedule"))))
+	mux.HandleFunc("/api/schedule/import", requireMethod(http.MethodPost, requireSession(requireUserType(fieldControllers, importSchedule))))
+	mux.HandleFunc("/api/schedule/export", requireMethod(http.MethodGet, requireSession(exportSchedule)))
+	mux.HandleFunc("/api/results", requireMethod(http.MethodGet, requireSession(getResults)))
+	mux.HandleFunc("/api/rankings", requireMethod(http.MethodGet, requireSession(getRankings)))
+	mux.HandleFunc("/api/rankings/snapshots", requireMethod(http.MethodGet, requireSession(getRankingSnapshots)))
+	mux.HandleFunc("/api/rankings/snapshots/latest", requireMethod(http.MethodGet, requireSession(getLatestRankingSnapshot)))
+	mux.HandleFunc("/api/selection", requireMethod(http.MethodGet, requireSession(getSelection)))
+	mux.HandleFunc("/api/selection/start", requireMethod(http.MethodPost, requireSession(commandHandler("startAllianceSelection"))))
+	mux.HandleFunc("/api/selection/pick", requireMethod(http.MethodPost, requireSession(commandHandler("pickTeam"))))
+	mux.HandleFunc("/api/selection/respond", requireMethod(http.MethodPost, requireSession(commandHandler("respondToPick"))))
+	mux.HandleFunc("/api/selection/backup", requireMethod(http.MethodPost, requireSession(commandHandler("setBackup"))))
+	mux.HandleFunc("/api/bracket", requireMethod(http.MethodGet, requireSession(getBracket)))
+	mux.HandleFunc("/api/bracket/generate", requireMethod(http.MethodPost, requireSession(commandHandler("generateBracket"))))
+	mux.HandleFunc("/api/scoring", requireMethod(http.MethodPost, requireSession(commandHandler("updateScoringData"))))
+	mux.HandleFunc("/api/fouls", requireMethod(http.MethodPost, requireSession(commandHandler("addFoul"))))
+	mux.HandleFunc("/api/history/", requireMethod(http.MethodGet, requireSession(getHistory)))
+}
+
+// Only lets a request through if it uses the right method
+func requireMethod(method string, handler http.HandlerFunc) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		if request.Method != method {
+			writeError(writer, http.StatusMethodNotAllowed, "this endpoint only accepts "+method)
+			return
+		}
+		handler(writer, request)
+	}
+}
+
+// Only lets a request through if it has the token of a logged in user
+func requireSession(handler authenticatedHandler) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		userSession, ok := getSession(getToken(request))
+		if !ok {
+			writeError(writer, http.StatusUnauthorized, "you need to login first")
+			return
+		}
+		handler(writer, request, userSession)
+	}
+}
+
+// Only lets a request through if the user is one of the allowed types (or an admin)
+func requireUserType(userTypes []database.UserType, handler authenticatedHandler) authenticatedHandler {
+	return func(writer http.ResponseWriter, request *http.Request, userSession session) {
+		if !isAllowed(userSession.User.UserType, userTypes) {
+			writeError(writer, http.StatusForbidden, "a "+userSession.User.UserType.String()+" isn't allowed to do that")
+			return
+		}
+		handler(writer, request, userSession)
+	}
+}
+
+// Runs a command with the request body as it's data
+func commandHandler(name string) authenticatedHandler {
+	return func(writer http.ResponseWriter, request *http.Request, userSession session) {
+		body, err := ioutil.ReadAll(request.Body)
+		if err != nil {
+			writeError(writer, http.StatusBadRequest, "couldn't read the request body")
+			return
+		}
+		if err := runCommand(name, userSession.User.UserType, body); err != nil {
+			writeCommandError(writer, err)
+			return
+		}
+		writeField(writer)
+	}
+}
+
+func getField(writer http.ResponseWriter, request *http.Request, userSession session) {
+	writeField(writer)
+}
+
+// Gets the score breakdown of the match being reviewed
+func getReview(writer http.ResponseWriter, request *http.Request, userSession session) {
+	review, err := field.CurrentField.GetReview()
+	if err != nil {
+		writeError(writer, http.StatusConflict, err.Error())
+		return
+	}
+	writeJSON(writer, http.StatusOK, review)
+}
+
+// GET lists the teams in each alliance station, POST adds one and DELETE removes one
+func teams(writer http.ResponseWriter, request *http.Request, userSession session) {
+	switch request.Method {
+	case http.MethodGet:
+		writeJSON(writer, http.StatusOK, field.CurrentField.GetAllianceStationToTeam())
+	case http.MethodPost:
+		commandHandler("addTeam")(writer, request, userSession)
+	case http.MethodDelete:
+		commandHandler("removeTeamByStation")(writer, request, userSession)
+	default:
+		writeError(writer, http.StatusMethodNotAllowed, "this endpoint only accepts GET, POST and DELETE")
+	}
+}
+
+// GET gets the event being run and POST creates a new one and selects it
+func currentEvent(writer http.ResponseWriter, request *http.Request, userSession session) {
+	switch request.Method {
+	case http.MethodGet:
+		event, err := database.GetCurrentEvent()
+		if err != nil {
+			writeError(writer, http.StatusNotFound, err.Error())
+			return
+		}
+		writeJSON(writer, http.StatusOK, event)
+	case http.MethodPost:
+		commandHandler("createEvent")(writer, request, userSession)
+	default:
+		writeError(writer, http.StatusMethodNotAllowed, "this endpoint only accepts GET and POST")
+	}
+}
+
+// Gets every event, newest first
+func getEvents(writer http.ResponseWriter, request *http.Request, userSession session) {
+	writeJSON(writer, http.StatusOK, database.GetAllEvents())
+}
+
+// GET lists the teams registered for the event, POST registers one and DELETE removes one
+func eventTeams(writer http.ResponseWriter, request *http.Request, userSession session) {
+	switch request.Method {
+	case http.MethodGet:
+		writeJSON(writer, http.StatusOK, database.GetAllTeams())
+	case http.MethodPost:
+		commandHandler("createTeam")(writer, request, userSession)
+	case http.MethodDelete:
+		commandHandler("deleteTeam")(writer, request, userSession)
+	default:
+		writeError(writer, http.StatusMethodNotAllowed, "this endpoint only accepts GET, POST and DELETE")
+	}
+}
+
+// Gets the schedule for the level in the query string, like ?level=QUALIFICATION
+func getSchedule(writer http.ResponseWriter, request *http.Request, userSession session) {
+	level, err := field.ParseLevel(request.URL.Query().Get("level"))
+	if err != nil {
+		writeError(writer, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(writer, http.StatusOK, database.GetSchedule(int(level)))
+}
+
+// Imports a team list from the request body, ?format= is csv or json
+func importTeams(writer http.ResponseWriter, request *http.Request, userSession session) {
+	format, err := schedule.ParseFormat(request.URL.Query().Get("format"))
+	if err != nil {
+		writeError(writer, http.StatusBadRequest, err.Error())
+		return
+	}
+	teams, err := schedule.ReadTeams(request.Body, format)
+	if err != nil {
+		writeError(writer, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := schedule.ImportTeams(teams); err != nil {
+		writeError(writer, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(writer, http.StatusOK, database.GetAllTeams())
+}
+
+// Exports the team list, ?format= is csv or json
+func exportTeams(writer http.ResponseWriter, request *http.Request, userSession session) {
+	format, err := schedule.ParseFormat(request.URL.Query().Get("format"))
+	if err != nil {
+		writeError(writer, http.StatusBadRequest, err.Error())
+		return
+	}
+	writer.Header().Set("Content-Type", contentType(format))
+	schedule.WriteTeams(writer, format, database.GetAllTeams())
+}
+
+// Imports the schedule for ?level= from the request body, ?format= is csv or json
+func importSchedule(writer http.ResponseWriter, request *http.Request, userSession session) {
+	level, err := field.ParseLevel(request.URL.Query().Get("level"))
+	if err != nil {
+		writeError(writer, http.StatusBadRequest, err.Error())
+		return
+	}
+	format, err := schedule.ParseFormat(request.URL.Query().Get("format"))
+	if err != nil {
+		writeError(writer, http.StatusBadRequest, err.Error())
+		return
+	}
+	matches, err := schedule.ReadSchedule(request.Body, format)
+	if err != nil {
+		writeError(writer, http.StatusBadRequest, err.Error())
+		return
+	}
+	if err := schedule.ImportSchedule(int(level), matches); err != nil {
+		writeError(writer, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(writer, http.StatusOK, database.GetSchedule(int(level)))
+}
+
+// Exports the schedule for ?level=, ?format= is csv or json
+func exportSchedule(writer http.ResponseWriter, request *http.Request, userSession session) {
+	level, err := field.ParseLevel(request.URL.Query().Get("level"))
+	if err != nil {
+		writeError(writer, http.StatusBadRequest, err.Error())
+		return
+	}
+	format, err := schedule.ParseFormat(request.URL.Query().Get("format"))
+	if err != nil {
+		writeError(writer, http.StatusBadRequest, err.Error())
+		return
+	}
+	writer.Header().Set("Content-Type", contentType(format))
+	schedule.WriteSchedule(writer, format, database.GetSchedule(int(level)))
+}
+
+func contentType(format schedule.Format) string {
+	if format == schedule.CSV {
+		return "text/csv"
+	}
+	return "application/json"
+}
+
+// Gets every committed result for the level in the query string, like ?level=QUALIFICATION
+func getResults(writer http.ResponseWriter, request *http.Request, userSession session) {
+	level, err := field.ParseLevel(request.URL.Query().Get("level"))
+	if err != nil {
+		writeError(writer, http.StatusBadRequest, err.Error())
+		return
+	}
+	writeJSON(writer, http.StatusOK, database.GetMatchResults(int(level)))
+}
+
+// Gets the qualification rankings
+func getRankings(writer http.ResponseWriter, request *http.Request, userSession session) {
+	writeJSON(writer, http.StatusOK, rankings.GetQualificationRankings())
+}
+
+// Gets the qualification rankings saved after every committed match, oldest first
+func getRankingSnapshots(writer http.ResponseWriter, request *http.Request, userSession session) {
+	snapshots, err := rankings.GetSnapshots()
+	if err != nil {
+		writeError(writer, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(writer, http.StatusOK, snapshots)
+}
+
+// Gets the qualification rankings saved after the last committed match
+func getLatestRankingSnapshot(writer http.ResponseWriter, request *http.Request, userSession session) {
+	snapshot, err := rankings.GetLatestSnapshot()
+	if err != nil {
+		writeError(writer, http.StatusNotFound, err.Error())
+		return
+	}
+	writeJSON(writer, http.StatusOK, snapshot)
+}
+
+// Gets the state of alliance selection
+func getSelection(writer http.ResponseWriter, request *http.Request, userSession session) {
+	currentSelection, err := selection.GetCurrentSelection()
+	if err != nil {
+		writeError(writer, http.StatusConflict, err.Error())
+		return
+	}
+	writeJSON(writer, http.StatusOK, currentSelection.Get())
+}
+
+// Gets the playoff bracket
+func getBracket(writer http.ResponseWriter, request *http.Request, userSession session) {
+	playoffBracket, err := bracket.GetBracket()
+	if err != nil {
+		writeError(writer, http.StatusConflict, err.Error())
+		return
+	}
+	writeJSON(writer, http.StatusOK, playoffBracket)
+}
+
+// Gets everything a team's driverstation reported during the current match, like /api/history/1234
+func getHistory(writer http.ResponseWriter, request *http.Request, userSession session) {
+	teamNum, err := strconv.Atoi(strings.TrimPrefix(request.URL.Path, "/api/history/"))
+	if err != nil {
+		writeError(writer, http.StatusBadRequest, "that isn't a team number")
+		return
+	}
+	history := field.CurrentField.GetDriverStationHistory(teamNum)
+	if history == nil {
+		writeError(writer, http.StatusNotFound, "that team doesn't have any history for this match")
+		return
+	}
+	writeJSON(writer, http.StatusOK, history)
+}
+
+// Bad request data is the client's fault, anything else means the field wasn't in a state to do it
+func writeCommandError(writer http.ResponseWriter, err error) {
+	switch err.(type) {
+	case requestError:
+		writeError(writer, http.StatusBadRequest, err.Error())
+	case permissionError:
+		writeError(writer, http.StatusForbidden, err.Error())
+	default:
+		writeError(writer, http.StatusConflict, err.Error())
+	}
+}
+
+// Decodes a JSON request body
+func decodeBody(request *http.Request, value interface{}) error {
+	if err := json.NewDecoder(request.Body).Decode(value); err != nil {
+		return errors.New("couldn't decode the request body: " + err.Error())
+	}
+	return nil
+}
+
+// Writes the whole field, it has to be encoded through ToJSON so it's locked
+func writeField(writer http.ResponseWriter) {
+	fieldJSON, err := field.CurrentField.ToJSON()
+	if err != nil {
+		writeError(writer, http.StatusInternalServerError, err.Error())
+		return
+	}
+	writeJSON(writer, http.StatusOK, fieldJSON)
+}
+
+func writeError(writer http.ResponseWriter, status int, message string) {
+	writeJSON(writer, status, apiError{Error: message})
+}
+
+func writeJSON(writer http.ResponseWriter, status int, data interface{}) {
+	writer.Header().Set("Content-Type", "application/json")
+	writer.WriteHeader(status)
+	json.NewEncoder(writer).Encode(data)
+}
@@ -0,0 +1,206 @@
+package web
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"github.com/McMackety/nevermore/database"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// How long a login lasts before the user has to enter their PIN again
+const sessionLength = time.Hour * 12
+
+// How many wrong PINs a user can enter before their logins are locked
+const maxLoginFailures = 5
+
+// How long the first lock lasts, it doubles with every wrong PIN after that up to maxLoginLockout
+const loginLockout = time.Second * 30
+const maxLoginLockout = time.Minute * 15
+
+// session is a logged in user
+type session struct {
+	User    database.User
+	Expires time.Time
+}
+
+var sessionsMutex sync.Mutex
+var sessions = make(map[string]session)
+
+// loginFailures is how many wrong PINs were entered for a user in a row
+type loginFailures struct {
+	Count       int
+	LockedUntil time.Time
+}
+
+var loginFailuresMutex sync.Mutex
+var failedLogins = make(map[string]*loginFailures)
+
+// lockedError is returned when a user has entered too many wrong PINs
+type lockedError struct {
+	message string
+}
+
+func (err lockedError) Error() string {
+	return err.message
+}
+
+// loginRequest is the body of a login
+type loginRequest struct {
+	Username string `json:"username"`
+	Pin      string `json:"pin"`
+}
+
+// loginResponse is sent back after a successful login
+type loginResponse struct {
+	Token    string            `json:"token"`
+	Username string            `json:"username"`
+	UserType database.UserType `json:"userType"`
+	Expires  time.Time         `json:"expires"`
+}
+
+// Checks a user's PIN and creates a session for them
+func login(username string, pin string) (string, session, error) {
+	if err := checkLoginLocked(username); err != nil {
+		return "", session{}, err
+	}
+	if !database.CheckUserPIN(username, pin) {
+		addLoginFailure(username)
+		return "", session{}, errors.New("invalid username or pin")
+	}
+	clearLoginFailures(username)
+	user, err := database.GetUserByUsername(username)
+	if err != nil {
+		return "", session{}, err
+	}
+	tokenBytes := make([]byte, 32)
+	if _, err := rand.Read(tokenBytes); err != nil {
+		return "", session{}, err
+	}
+	token := hex.EncodeToString(tokenBytes)
+	newSession := session{User: user, Expires: time.Now().Add(sessionLength)}
+
+	sessionsMutex.Lock()
+	defer sessionsMutex.Unlock()
+	sessions[token] = newSession
+	return token, newSession, nil
+}
+
+// Returns a lockedError if the user entered too many wrong PINs and has to wait before trying again
+func checkLoginLocked(username string) error {
+	loginFailuresMutex.Lock()
+	defer loginFailuresMutex.Unlock()
+	failures, ok := failedLogins[strings.ToLower(username)]
+	if !ok || !time.Now().Before(failures.LockedUntil) {
+		return nil
+	}
+	wait := time.Until(failures.LockedUntil).Round(time.Second)
+	return lockedError{message: "too many wrong pins, try again in " + wait.String()}
+}
+
+// Counts a wrong PIN, once there's been maxLoginFailures in a row the user is locked for longer after each one
+func addLoginFailure(username string) {
+	loginFailuresMutex.Lock()
+	defer loginFailuresMutex.Unlock()
+	failures, ok := failedLogins[strings.ToLower(username)]
+	if !ok {
+		failures = &loginFailures{}
+		failedLogins[strings.ToLower(username)] = failures
+	}
+	failures.Count++
+	if failures.Count < maxLoginFailures {
+		return
+	}
+	lockout := maxLoginLockout
+	if doublings := failures.Count - maxLoginFailures; doublings < 10 && loginLockout<<doublings < maxLoginLockout {
+		lockout = loginLockout << doublings
+	}
+	failures.LockedUntil = time.Now().Add(lockout)
+}
+
+func clearLoginFailures(username string) {
+	loginFailuresMutex.Lock()
+	defer loginFailuresMutex.Unlock()
+	delete(failedLogins, strings.ToLower(username))
+}
+
+// Ends a session
+func logout(token string) {
+	sessionsMutex.Lock()
+	defer sessionsMutex.Unlock()
+	delete(sessions, token)
+}
+
+// Gets the session for a token, if it exists and hasn't expired.
+// The session is dropped if it's user was removed or changed since they logged in, so they have to login again.
+func getSession(token string) (session, bool) {
+	sessionsMutex.Lock()
+	userSession, ok := sessions[token]
+	sessionsMutex.Unlock()
+	if !ok {
+		return session{}, false
+	}
+	if time.Now().After(userSession.Expires) {
+		logout(token)
+		return session{}, false
+	}
+	user, err := database.GetUserByID(userSession.User.ID)
+	if err != nil || !user.UpdatedAt.Equal(userSession.User.UpdatedAt) {
+		logout(token)
+		return session{}, false
+	}
+	return userSession, true
+}
+
+// Gets the token from the Authorization header, "Bearer <token>"
+func getToken(request *http.Request) string {
+	header := request.Header.Get("Authorization")
+	if strings.HasPrefix(header, "Bearer ") {
+		return strings.TrimPrefix(header, "Bearer ")
+	}
+	return ""
+}
+
+// Checks if a user type is in a list of allowed user types, admins are allowed to do anything
+func isAllowed(userType database.UserType, allowed []database.UserType) bool {
+	if userType == database.ADMIN {
+		return true
+	}
+	for _, allowedType := range allowed {
+		if userType == allowedType {
+			return true
+		}
+	}
+	return false
+}
+
+// Logs a user in, the token it returns goes in the Authorization header of every other request
+func loginHandler(writer http.ResponseWriter, request *http.Request) {
+	var body loginRequest
+	if err := decodeBody(request, &body); err != nil {
+		writeError(writer, http.StatusBadRequest, err.Error())
+		return
+	}
+	token, userSession, err := login(body.Username, body.Pin)
+	if _, ok := err.(lockedError); ok {
+		writeError(writer, http.StatusTooManyRequests, err.Error())
+		return
+	} else if err != nil {
+		writeError(writer, http.StatusUnauthorized, err.Error())
+		return
+	}
+	writeJSON(writer, http.StatusOK, loginResponse{
+		Token:    token,
+		Username: userSession.User.Username,
+		UserType: userSession.User.UserType,
+		Expires:  userSession.Expires,
+	})
+}
+
+func logoutHandler(writer http.ResponseWriter, request *http.Request) {
+	logout(getToken(request))
+	writeJSON(writer, http.StatusOK, struct{}{})
+}
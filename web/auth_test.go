@@ -0,0 +1,75 @@
+package web
+
+import (
+	"testing"
+	"time"
+
+	"github.com/McMackety/nevermore/database"
+	"github.com/McMackety/nevermore/database/testutil"
+)
+
+func createTestUser(t *testing.T, username string) {
+	t.Helper()
+	testutil.UseTestDatabase(t)
+	if err := database.CreateUser(username, database.SCORER, "1234"); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestLoginIsLockedAfterTooManyWrongPins(t *testing.T) {
+	createTestUser(t, "scorer")
+	defer clearLoginFailures("scorer")
+	for i := 0; i < maxLoginFailures; i++ {
+		if _, _, err := login("scorer", "0000"); err == nil {
+			t.Fatal("expected a wrong pin not to login")
+		}
+	}
+	if _, _, err := login("scorer", "1234"); err == nil {
+		t.Fatal("expected the right pin not to login while the user is locked")
+	} else if _, ok := err.(lockedError); !ok {
+		t.Errorf("expected a lockedError, got %v", err)
+	}
+
+	// Pretend the lock ran out
+	loginFailuresMutex.Lock()
+	failedLogins["scorer"].LockedUntil = time.Now()
+	loginFailuresMutex.Unlock()
+	if _, _, err := login("scorer", "1234"); err != nil {
+		t.Fatalf("expected the right pin to login once the lock ran out, got %v", err)
+	}
+	if _, _, err := login("scorer", "0000"); err == nil || checkLoginLocked("scorer") != nil {
+		t.Error("expected a successful login to reset the wrong pins")
+	}
+}
+
+func TestSessionsEndWhenTheUserChanges(t *testing.T) {
+	createTestUser(t, "scorer")
+	token, _, err := login("scorer", "1234")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := getSession(token); !ok {
+		t.Fatal("expected the session to be valid right after logging in")
+	}
+	user, err := database.GetUserByUsername("scorer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := user.SetPIN("5678"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := getSession(token); ok {
+		t.Error("expected changing the user's pin to end their session")
+	}
+
+	token, _, err = login("scorer", "5678")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := database.DeleteUser("scorer"); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := getSession(token); ok {
+		t.Error("expected removing the user to end their session")
+	}
+}
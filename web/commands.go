@@ -0,0 +1,412 @@
+package web
+
+import (
+	"encoding/json"
+	"errors"
+	"github.com/McMackety/nevermore/bracket"
+	"github.com/McMackety/nevermore/database"
+	"github.com/McMackety/nevermore/field"
+	"github.com/McMackety/nevermore/schedule"
+	"github.com/McMackety/nevermore/selection"
+)
+
+// setupMatchData is the data for the setupMatch command
+type setupMatchData struct {
+	MatchNumber int         `json:"matchNum"`
+	Level       field.Level `json:"matchLevel"`
+	Red1        int         `json:"red1"`
+	Red2        int         `json:"red2"`
+	Red3        int         `json:"red3"`
+	Blue1       int         `json:"blue1"`
+	Blue2       int         `json:"blue2"`
+	Blue3       int         `json:"blue3"`
+}
+
+// teamData is the data for the addTeam and removeTeamByStation commands
+type teamData struct {
+	Station    field.AllianceStation `json:"allianceStation"`
+	TeamNumber int                   `json:"teamNum"`
+}
+
+// scoringData is the data for the updateScoringData command
+type scoringData struct {
+	Alliance field.Alliance         `json:"alliance"`
+	Data     map[string]interface{} `json:"data"`
+}
+
+// foulData is the data for the addFoul command, Alliance is the alliance that committed the foul
+type foulData struct {
+	Alliance   field.Alliance `json:"alliance"`
+	IsTechFoul bool           `json:"isTechFoul"`
+}
+
+// setFoulsData is the data for the setFouls command, Alliance is the alliance that committed the fouls
+type setFoulsData struct {
+	Alliance  field.Alliance `json:"alliance"`
+	Fouls     int            `json:"fouls"`
+	TechFouls int            `json:"techFouls"`
+}
+
+// cardData is the data for the setCard command
+type cardData struct {
+	Station field.AllianceStation `json:"allianceStation"`
+	Card    field.Card            `json:"card"`
+}
+
+// pauseData is the data for the pauseMatch command
+type pauseData struct {
+	Reason string `json:"reason"`
+}
+
+// stationData is the data for commands that act on a single alliance station
+type stationData struct {
+	Station field.AllianceStation `json:"allianceStation"`
+}
+
+// bypassData is the data for the setBypassed command
+type bypassData struct {
+	Station  field.AllianceStation `json:"allianceStation"`
+	Bypassed bool                  `json:"bypassed"`
+}
+
+// disableData is the data for the setDisabled command
+type disableData struct {
+	Station  field.AllianceStation `json:"allianceStation"`
+	Disabled bool                  `json:"disabled"`
+}
+
+// levelData is the data for the setupNextMatch command
+type levelData struct {
+	Level field.Level `json:"matchLevel"`
+}
+
+// generateScheduleData is the data for the generateSchedule command, MinTurnaround is in matches
+type generateScheduleData struct {
+	Level          field.Level `json:"matchLevel"`
+	MatchesPerTeam int         `json:"matchesPerTeam"`
+	MinTurnaround  int         `json:"minTurnaround"`
+}
+
+// startSelectionData is the data for the startAllianceSelection command
+type startSelectionData struct {
+	AllianceCount    int `json:"allianceCount"`
+	TeamsPerAlliance int `json:"teamsPerAlliance"`
+}
+
+// pickData is the data for the pickTeam command
+type pickData struct {
+	TeamNumber int `json:"teamNum"`
+}
+
+// respondData is the data for the respondToPick command
+type respondData struct {
+	Accept bool `json:"accept"`
+}
+
+// backupData is the data for the setBackup command, a TeamNumber of 0 removes the backup
+type backupData struct {
+	Alliance     int `json:"alliance"`
+	TeamNumber   int `json:"teamNum"`
+	ReplacedTeam int `json:"replacedTeamNum"` // The team on the alliance the backup plays instead of
+}
+
+// eventData is the data for the selectEvent command
+type eventData struct {
+	Code string `json:"code"`
+}
+
+// bracketData is the data for the generateBracket command
+type bracketData struct {
+	Format string `json:"format"`
+}
+
+// requestError means the data sent with a command was bad, rather than the field refusing it
+type requestError struct {
+	error
+}
+
+// permissionError means the user isn't allowed to run the command
+type permissionError struct {
+	error
+}
+
+// command is something a client can do to the field, only the listed user types (and admins) can run it
+type command struct {
+	UserTypes []database.UserType
+	Run       func(data json.RawMessage) error
+}
+
+// The user types allowed to run each group of commands
+var fieldControllers = []database.UserType{database.FTA}
+var referees = []database.UserType{database.HEADREFEREE, database.REFEREE}
+var scorers = []database.UserType{database.SCORER}
+var headReferees = []database.UserType{database.HEADREFEREE}
+var emergencyStoppers = []database.UserType{database.FTA, database.HEADREFEREE}
+
+// Decodes the data sent with a command
+func decodeData(data json.RawMessage, value interface{}) error {
+	if err := json.Unmarshal(data, value); err != nil {
+		return requestError{errors.New("couldn't decode the command's data: " + err.Error())}
+	}
+	return nil
+}
+
+// Fouls and scoring data can only be given to the red or blue alliance
+func checkAlliance(alliance field.Alliance) error {
+	if alliance != field.RED && alliance != field.BLUE {
+		return requestError{errors.New("the alliance has to be RED or BLUE")}
+	}
+	return nil
+}
+
+// Every command a client can run, mirrors the CLI in main.go
+var commands = map[string]command{
+	"setupMatch": {fieldControllers, func(data json.RawMessage) error {
+		var setup setupMatchData
+		if err := decodeData(data, &setup); err != nil {
+			return err
+		}
+		return field.CurrentField.SetupField(setup.MatchNumber, setup.Level, setup.Red1, setup.Red2, setup.Red3, setup.Blue1, setup.Blue2, setup.Blue3)
+	}},
+	"setupNextMatch": {fieldControllers, func(data json.RawMessage) error {
+		var level levelData
+		if err := decodeData(data, &level); err != nil {
+			return err
+		}
+		return field.CurrentField.SetupNextMatch(level.Level)
+	}},
+	"generateSchedule": {fieldControllers, func(data json.RawMessage) error {
+		var generate generateScheduleData
+		if err := decodeData(data, &generate); err != nil {
+			return err
+		}
+		if _, err := schedule.GenerateForTeams(int(generate.Level), generate.MatchesPerTeam, generate.MinTurnaround); err != nil {
+			return requestError{err}
+		}
+		return nil
+	}},
+	"createEvent": {fieldControllers, func(data json.RawMessage) error {
+		var event database.Event
+		if err := decodeData(data, &event); err != nil {
+			return err
+		}
+		if err := database.CreateEvent(&event); err != nil {
+			return requestError{err}
+		}
+		field.CurrentField.SetEventName(event.Code)
+		return nil
+	}},
+	"selectEvent": {fieldControllers, func(data json.RawMessage) error {
+		var selected eventData
+		if err := decodeData(data, &selected); err != nil {
+			return err
+		}
+		event, err := database.SelectEvent(selected.Code)
+		if err != nil {
+			return requestError{err}
+		}
+		field.CurrentField.SetEventName(event.Code)
+		return nil
+	}},
+	"createTeam": {fieldControllers, func(data json.RawMessage) error {
+		var team database.Team
+		if err := decodeData(data, &team); err != nil {
+			return err
+		}
+		if err := database.CreateTeam(&team); err != nil {
+			return requestError{err}
+		}
+		return nil
+	}},
+	"deleteTeam": {fieldControllers, func(data json.RawMessage) error {
+		var team database.Team
+		if err := decodeData(data, &team); err != nil {
+			return err
+		}
+		return database.DeleteTeam(team.Number)
+	}},
+	"startAllianceSelection": {fieldControllers, func(data json.RawMessage) error {
+		var start startSelectionData
+		if err := decodeData(data, &start); err != nil {
+			return err
+		}
+		return selection.Start(start.AllianceCount, start.TeamsPerAlliance)
+	}},
+	"pickTeam": {fieldControllers, func(data json.RawMessage) error {
+		var pick pickData
+		if err := decodeData(data, &pick); err != nil {
+			return err
+		}
+		currentSelection, err := selection.GetCurrentSelection()
+		if err != nil {
+			return err
+		}
+		return currentSelection.Pick(pick.TeamNumber)
+	}},
+	"respondToPick": {fieldControllers, func(data json.RawMessage) error {
+		var respond respondData
+		if err := decodeData(data, &respond); err != nil {
+			return err
+		}
+		currentSelection, err := selection.GetCurrentSelection()
+		if err != nil {
+			return err
+		}
+		return currentSelection.Respond(respond.Accept)
+	}},
+	"setBackup": {fieldControllers, func(data json.RawMessage) error {
+		var backup backupData
+		if err := decodeData(data, &backup); err != nil {
+			return err
+		}
+		currentSelection, err := selection.GetCurrentSelection()
+		if err != nil {
+			return err
+		}
+		return currentSelection.SetBackup(backup.Alliance, backup.TeamNumber, backup.ReplacedTeam)
+	}},
+	"generateBracket": {fieldControllers, func(data json.RawMessage) error {
+		var generate bracketData
+		if err := decodeData(data, &generate); err != nil {
+			return err
+		}
+		format, err := bracket.ParseFormat(generate.Format)
+		if err != nil {
+			return requestError{err}
+		}
+		_, err = bracket.Generate(format)
+		return err
+	}},
+	"startMatch": {fieldControllers, func(data json.RawMessage) error {
+		return field.CurrentField.StartField()
+	}},
+	"stopMatch": {fieldControllers, func(data json.RawMessage) error {
+		return field.CurrentField.StopField(false)
+	}},
+	"abortMatch": {fieldControllers, func(data json.RawMessage) error {
+		return field.CurrentField.StopField(true)
+	}},
+	"pauseMatch": {fieldControllers, func(data json.RawMessage) error {
+		var pause pauseData
+		if err := decodeData(data, &pause); err != nil {
+			return err
+		}
+		if pause.Reason == "" {
+			return requestError{errors.New("a reason is needed to pause the match")}
+		}
+		return field.CurrentField.PauseField(pause.Reason)
+	}},
+	"resumeMatch": {fieldControllers, func(data json.RawMessage) error {
+		return field.CurrentField.ResumeField()
+	}},
+	"emergencyStop": {emergencyStoppers, func(data json.RawMessage) error {
+		var station stationData
+		if err := decodeData(data, &station); err != nil {
+			return err
+		}
+		return field.CurrentField.EmergencyStopStation(station.Station)
+	}},
+	"autonomousStop": {emergencyStoppers, func(data json.RawMessage) error {
+		var station stationData
+		if err := decodeData(data, &station); err != nil {
+			return err
+		}
+		return field.CurrentField.AutonomousStopStation(station.Station)
+	}},
+	"setBypassed": {fieldControllers, func(data json.RawMessage) error {
+		var bypass bypassData
+		if err := decodeData(data, &bypass); err != nil {
+			return err
+		}
+		return field.CurrentField.SetStationBypassed(bypass.Station, bypass.Bypassed)
+	}},
+	"setDisabled": {fieldControllers, func(data json.RawMessage) error {
+		var disable disableData
+		if err := decodeData(data, &disable); err != nil {
+			return err
+		}
+		return field.CurrentField.SetStationDisabled(disable.Station, disable.Disabled)
+	}},
+	"enableAll": {fieldControllers, func(data json.RawMessage) error {
+		field.CurrentField.EnableAllRobots()
+		return nil
+	}},
+	"disableAll": {fieldControllers, func(data json.RawMessage) error {
+		field.CurrentField.DisableAllRobots()
+		return nil
+	}},
+	"startTest": {fieldControllers, func(data json.RawMessage) error {
+		field.CurrentField.SetMatchLevel(field.MATCHTEST)
+		return nil
+	}},
+	"stopTest": {fieldControllers, func(data json.RawMessage) error {
+		field.CurrentField.SetMatchLevel(field.PRACTICE)
+		return nil
+	}},
+	"addTeam": {fieldControllers, func(data json.RawMessage) error {
+		var team teamData
+		if err := decodeData(data, &team); err != nil {
+			return err
+		}
+		return field.CurrentField.AddTeam(team.Station, team.TeamNumber)
+	}},
+	"removeTeamByStation": {fieldControllers, func(data json.RawMessage) error {
+		var team teamData
+		if err := decodeData(data, &team); err != nil {
+			return err
+		}
+		return field.CurrentField.RemoveTeamByStation(team.Station)
+	}},
+	"commitReview": {headReferees, func(data json.RawMessage) error {
+		return field.CurrentField.CommitReview()
+	}},
+	"setFouls": {headReferees, func(data json.RawMessage) error {
+		var fouls setFoulsData
+		if err := decodeData(data, &fouls); err != nil {
+			return err
+		}
+		if err := checkAlliance(fouls.Alliance); err != nil {
+			return err
+		}
+		return field.CurrentField.SetFouls(fouls.Alliance, fouls.Fouls, fouls.TechFouls)
+	}},
+	"setCard": {headReferees, func(data json.RawMessage) error {
+		var card cardData
+		if err := decodeData(data, &card); err != nil {
+			return err
+		}
+		return field.CurrentField.SetCard(card.Station, card.Card)
+	}},
+	"updateScoringData": {scorers, func(data json.RawMessage) error {
+		var scoring scoringData
+		if err := decodeData(data, &scoring); err != nil {
+			return err
+		}
+		if err := checkAlliance(scoring.Alliance); err != nil {
+			return err
+		}
+		return field.CurrentField.UpdateScoringData(scoring.Alliance, scoring.Data)
+	}},
+	"addFoul": {referees, func(data json.RawMessage) error {
+		var foul foulData
+		if err := decodeData(data, &foul); err != nil {
+			return err
+		}
+		if err := checkAlliance(foul.Alliance); err != nil {
+			return err
+		}
+		return field.CurrentField.AddFoul(foul.Alliance, foul.IsTechFoul)
+	}},
+}
+
+// Runs a command by name as a user
+func runCommand(name string, userType database.UserType, data json.RawMessage) error {
+	command, ok := commands[name]
+	if !ok {
+		return requestError{errors.New("unknown command " + name)}
+	}
+	if !isAllowed(userType, command.UserTypes) {
+		return permissionError{errors.New("a " + userType.String() + " isn't allowed to run " + name)}
+	}
+	return command.Run(data)
+}
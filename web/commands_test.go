@@ -0,0 +1,17 @@
+package web
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/McMackety/nevermore/database"
+)
+
+func TestAllianceCommandsRejectOtherAlliances(t *testing.T) {
+	for _, name := range []string{"updateScoringData", "addFoul", "setFouls"} {
+		err := runCommand(name, database.ADMIN, json.RawMessage(`{"alliance": 2}`))
+		if _, ok := err.(requestError); !ok {
+			t.Errorf("expected %s to return a requestError for alliance 2, got %v", name, err)
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package web
+
+import (
+	"github.com/McMackety/nevermore/config"
+	"log"
+	"net/http"
+)
+
+// CurrentHub is the hub every WebSocket client connects to
+var CurrentHub *Hub
+
+// Starts the web server for the WebSocket and API, this should be ran after the field is created
+func StartServer() {
+	CurrentHub = createHub()
+	go CurrentHub.run()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ws", CurrentHub.serveWebSocket)
+	registerAPI(mux)
+
+	address := config.DefaultConfig.WebSocketListenAddress
+	go func() {
+		log.Println("The FMS started a web server on " + address + "!")
+		if err := http.ListenAndServe(address, mux); err != nil {
+			log.Println("Couldn't start the web server: " + err.Error())
+		}
+	}()
+}
@@ -0,0 +1,142 @@
+package web
+
+import (
+	"encoding/json"
+	"github.com/McMackety/nevermore/events"
+	"github.com/gorilla/websocket"
+	"log"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// How long a client has to read a message before it's dropped
+const writeTimeout = time.Second * 10
+
+var upgrader = websocket.Upgrader{
+	// Displays and tablets are served from other machines on the field network
+	CheckOrigin: func(request *http.Request) bool { return true },
+}
+
+// Hub sends every field event to every connected WebSocket client
+type Hub struct {
+	mutex   sync.Mutex
+	clients map[*client]bool
+}
+
+type client struct {
+	conn *websocket.Conn
+	send chan interface{}
+}
+
+// commandMessage is what clients send to control the field, Token comes from logging in through the API
+type commandMessage struct {
+	Command string          `json:"command"`
+	Token   string          `json:"token"`
+	Data    json.RawMessage `json:"data"`
+}
+
+// commandResult is sent back to the client that ran a command
+type commandResult struct {
+	Command string `json:"command"`
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+}
+
+func createHub() *Hub {
+	return &Hub{
+		clients: make(map[*client]bool),
+	}
+}
+
+// Sends every published event to all of the clients
+func (hub *Hub) run() {
+	subscriber := events.Subscribe()
+	for event := range subscriber {
+		hub.mutex.Lock()
+		for client := range hub.clients {
+			select {
+			case client.send <- event:
+			default:
+				// The client isn't keeping up, drop it so it doesn't hold everyone else back
+				hub.removeClient(client)
+			}
+		}
+		hub.mutex.Unlock()
+	}
+}
+
+// Upgrades a HTTP request to a WebSocket and registers the client
+func (hub *Hub) serveWebSocket(writer http.ResponseWriter, request *http.Request) {
+	conn, err := upgrader.Upgrade(writer, request, nil)
+	if err != nil {
+		log.Println("Couldn't upgrade a WebSocket connection: " + err.Error())
+		return
+	}
+	newClient := &client{
+		conn: conn,
+		send: make(chan interface{}, 256),
+	}
+	hub.mutex.Lock()
+	hub.clients[newClient] = true
+	hub.mutex.Unlock()
+
+	go newClient.writeLoop()
+	newClient.readLoop(hub)
+}
+
+// Must be called with the hub's mutex held
+func (hub *Hub) removeClient(client *client) {
+	if _, ok := hub.clients[client]; ok {
+		delete(hub.clients, client)
+		close(client.send)
+	}
+}
+
+// Writes everything sent to the client, one message at a time
+func (client *client) writeLoop() {
+	defer client.conn.Close()
+	for message := range client.send {
+		client.conn.SetWriteDeadline(time.Now().Add(writeTimeout))
+		if err := client.conn.WriteJSON(message); err != nil {
+			return
+		}
+	}
+	client.conn.WriteMessage(websocket.CloseMessage, []byte{})
+}
+
+// Reads commands from the client until it disconnects
+func (client *client) readLoop(hub *Hub) {
+	defer func() {
+		hub.mutex.Lock()
+		hub.removeClient(client)
+		hub.mutex.Unlock()
+	}()
+	for {
+		var message commandMessage
+		if err := client.conn.ReadJSON(&message); err != nil {
+			return
+		}
+		result := commandResult{Command: message.Command, Success: true}
+		if userSession, ok := getSession(message.Token); !ok {
+			result.Success = false
+			result.Error = "you need to login first"
+		} else if err := runCommand(message.Command, userSession.User.UserType, message.Data); err != nil {
+			result.Success = false
+			result.Error = err.Error()
+		}
+		hub.mutex.Lock()
+		if _, ok := hub.clients[client]; ok {
+			select {
+			case client.send <- events.Event{Name: "commandResult", Data: mustMarshal(result)}:
+			default:
+			}
+		}
+		hub.mutex.Unlock()
+	}
+}
+
+func mustMarshal(data interface{}) json.RawMessage {
+	encoded, _ := json.Marshal(data)
+	return encoded
+}